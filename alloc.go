@@ -1,30 +1,98 @@
+// Package alloc stores values in allocators to keep them off the go heap.
+//
+// The containers, like List, BTree and Interner, are small headers which
+// point at their data in an allocator. Methods which change a container
+// update its header, so keep the header in one place and pass it by
+// pointer. A copy goes out of date as soon as the original changes.
 package alloc
 
 import (
 	"errors"
+	"fmt"
 	"unsafe"
 )
 
 var (
 	ErrMemoryExhausted = errors.New("memory exhausted")
 	ErrOutOfRange      = errors.New("offset out of range")
+	ErrFull            = errors.New("container is full")
 )
 
+// AllocError is returned when an allocator can not make an allocation. It
+// matches ErrMemoryExhausted with errors.Is, and carries the details of the
+// allocation which failed
+type AllocError struct {
+	// Size and Alignment are what was requested
+	Size      uintptr
+	Alignment uintptr
+	// Available is the memory the allocator had left
+	Available uintptr
+	// Allocator is the kind of allocator, like PageAllocator
+	Allocator string
+}
+
+// Error describes the failed allocation
+func (e *AllocError) Error() string {
+	return fmt.Sprintf("%s: memory exhausted allocating %d bytes aligned to %d, %d bytes available",
+		e.Allocator, e.Size, e.Alignment, e.Available)
+}
+
+// Unwrap returns ErrMemoryExhausted so errors.Is matches it
+func (e *AllocError) Unwrap() error {
+	return ErrMemoryExhausted
+}
+
 // Allocators are used to create an allocation of the
 type Allocator interface {
 	// Alloc creates a new item in memory with a size defined by the parameter
 	// it returns the offset within allocated memory to the location. If any
 	// errors occured they will be returned
+	//
+	// Allocations are made one after another, so allocating 0 bytes with an
+	// alignment of 1 returns where the next allocation will start without
+	// using any memory. Buffer relies on this to grow in place.
+	//
+	// The memory is not cleared. After a Reset it still holds whatever was
+	// there before, so anything which needs zeroed memory clears it itself
 	Alloc(size uintptr, alignment uintptr) (offset uintptr, err error)
 
 	// Offset takes the parameter offset, and returns the actual pointer to the
 	// location.
 	Offset(offset uintptr) (ptr unsafe.Pointer)
 
+	// OffsetChecked is like Offset, but returns ErrOutOfRange unless size
+	// bytes at offset are within the memory which has been allocated
+	OffsetChecked(offset uintptr, size uintptr) (ptr unsafe.Pointer, err error)
+
 	// Available returns the memory remaining until the allocator is exhausted
 	Available() uintptr
 }
 
+// ResetAllocator is an Allocator which can be reset, making all of its
+// memory available again. PageAllocator and ExpandingAllocator both
+// implement this
+type ResetAllocator interface {
+	Allocator
+
+	// Reset sets the head back to 0, Any allocations relying on these
+	// bytes will be overwritten over time
+	Reset()
+}
+
+// zeroSized is pointed to for checked offsets with no size, since the
+// offset could be the very end of the allocator's memory
+var zeroSized struct{}
+
+// checkRange returns ErrOutOfRange unless size bytes at offset fit within
+// the allocated extent
+func checkRange(offset, size, extent uintptr) error {
+	if end := offset + size; end < offset || end > extent {
+		return fmt.Errorf("%w: %d bytes at offset %d, %d bytes allocated", ErrOutOfRange, size, offset, extent)
+	}
+
+	return nil
+}
+
 // New will create a new type in the allocator, and return a pointer
 // to that type
 func New[T any](a Allocator) (Ptr[T], error) {
@@ -32,8 +100,11 @@ func New[T any](a Allocator) (Ptr[T], error) {
 		unsafe.Sizeof(*new(T)),
 		unsafe.Alignof(*new(T)),
 	)
+	if err != nil {
+		return Ptr[T]{}, err
+	}
 
-	return Ptr[T]{offset: offset, alloc: a}, err
+	return Ptr[T]{offset: offset, alloc: a}, nil
 }
 
 // Must wraps any allocatation functions and panics if an error occurs
@@ -51,6 +122,13 @@ type Primitive[T any] interface {
 	Cast() T
 }
 
+// Comparable is implemented by types which can be ordered, like String. Cmp
+// returns -1 if the value is less than val, 0 if they are equal and +1 if
+// the value is greater than val
+type Comparable[T any] interface {
+	Cmp(val T) int
+}
+
 // allocatorAlignment makes sure the byte slice is aligned to the larges possible size
 // which is 8. Then when we copy things over everything stays aligned
 const allocatorAlignment = 8
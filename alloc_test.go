@@ -1,6 +1,7 @@
 package alloc
 
 import (
+	"errors"
 	"math"
 	"testing"
 
@@ -34,6 +35,100 @@ func TestPtr(t *testing.T) {
 	assert.Equal(t, *(i.Deref()), uint64(100))
 }
 
+func TestPageAllocatorExhausted(t *testing.T) {
+	arena := NewPageAllocator()
+	_, err := arena.Alloc(4000, 8)
+	assert.NoError(t, err)
+
+	_, err = arena.Alloc(200, 8)
+	assert.ErrorIs(t, err, ErrMemoryExhausted)
+
+	var allocErr *AllocError
+	if assert.True(t, errors.As(err, &allocErr)) {
+		assert.Equal(t, uintptr(200), allocErr.Size)
+		assert.Equal(t, uintptr(8), allocErr.Alignment)
+		assert.Equal(t, uintptr(96), allocErr.Available)
+		assert.Equal(t, "PageAllocator", allocErr.Allocator)
+	}
+
+	// sizes which would wrap around are an error, not a panic
+	_, err = arena.Alloc(math.MaxUint64, 1)
+	assert.ErrorIs(t, err, ErrMemoryExhausted)
+
+	// the failed allocations didn't use any memory
+	assert.Equal(t, uintptr(96), arena.Available())
+
+	arr := Must(NewArray[uint64](&arena, 4)).Deref()
+	_, err = arr.Expand(8)
+	assert.ErrorIs(t, err, ErrMemoryExhausted)
+
+	p, err := New[[128]byte](&arena)
+	assert.ErrorIs(t, err, ErrMemoryExhausted)
+	assert.True(t, p.IsNull())
+
+	_, err = NewArray[uint64](&arena, 12)
+	assert.ErrorIs(t, err, ErrMemoryExhausted)
+
+	_, err = NewString(&arena, string(make([]byte, 100)))
+	assert.ErrorIs(t, err, ErrMemoryExhausted)
+
+	// growing containers pass the error on, and are left as they were
+	page := NewPageAllocator()
+	obj := Must(NewObject[int, Int, [1500]byte](&page, 1)).Deref()
+	assert.NoError(t, obj.Set(1, [1500]byte{1}))
+	assert.ErrorIs(t, obj.Set(2, [1500]byte{2}), ErrMemoryExhausted)
+	assert.Equal(t, 1, obj.Len())
+	v, _ := obj.Get(1)
+	assert.Equal(t, byte(1), v[0])
+}
+
+func TestExpandingAllocatorTooLarge(t *testing.T) {
+	arena := NewExpandingAllocator(8)
+	_, err := arena.Alloc(math.MaxUint64, 1)
+	assert.ErrorIs(t, err, ErrMemoryExhausted)
+
+	_, err = NewArray[uint64](&arena, -1)
+	assert.ErrorIs(t, err, ErrMemoryExhausted)
+}
+
+func TestOffsetChecked(t *testing.T) {
+	page, expanding := NewPageAllocator(), NewExpandingAllocator(8)
+	for name, arena := range map[string]ResetAllocator{"page": &page, "expanding": &expanding} {
+		t.Run(name, func(t *testing.T) {
+			_, err := arena.OffsetChecked(0, 1)
+			assert.ErrorIs(t, err, ErrOutOfRange)
+
+			i := Must(New[uint64](arena))
+			i.Set(42)
+
+			p, err := arena.OffsetChecked(i.offset, 8)
+			assert.NoError(t, err)
+			assert.Equal(t, uint64(42), *(*uint64)(p))
+
+			_, err = arena.OffsetChecked(i.offset, 9)
+			assert.ErrorIs(t, err, ErrOutOfRange)
+			_, err = arena.OffsetChecked(i.offset+4, math.MaxUint64)
+			assert.ErrorIs(t, err, ErrOutOfRange)
+
+			// nothing to read at the end is fine
+			_, err = arena.OffsetChecked(8, 0)
+			assert.NoError(t, err)
+
+			v, err := i.TryDeref()
+			assert.NoError(t, err)
+			assert.Equal(t, uint64(42), *v)
+
+			// a Ptr past the allocated memory, like one kept after a Reset
+			arena.Reset()
+			_, err = i.TryDeref()
+			assert.ErrorIs(t, err, ErrOutOfRange)
+		})
+	}
+
+	_, err := Ptr[int]{}.TryDeref()
+	assert.ErrorIs(t, err, ErrOutOfRange)
+}
+
 func BenchmarkAlloc(b *testing.B) {
 	b.Run("control", func(b *testing.B) {
 		b.ReportAllocs()
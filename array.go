@@ -2,6 +2,7 @@ package alloc
 
 import (
 	"iter"
+	"slices"
 	"unsafe"
 )
 
@@ -17,6 +18,13 @@ type Array[T any] struct {
 // be reflected in the slice, however if you append it will allocate a new slice and will
 // no longer be in the allocator
 func (s Array[T]) Slice() []T {
+	// an empty array can be a zero Array with no allocator, or a view at
+	// the very end of the allocated memory. Neither can be dereferenced,
+	// and there is nothing to read anyway
+	if s.len == 0 {
+		return nil
+	}
+
 	return unsafe.Slice((*T)(unsafe.Pointer(s.data.Deref())), s.len)
 }
 
@@ -25,6 +33,93 @@ func (s Array[T]) Length() int {
 	return s.len
 }
 
+// ptr returns a Ptr to the element at index i
+func (s Array[T]) ptr(i int) Ptr[T] {
+	return Ptr[T]{
+		offset: s.data.offset + uintptr(i)*unsafe.Sizeof(*new(T)),
+		alloc:  s.data.alloc,
+	}
+}
+
+// Sub returns the elements from start up to end as a new Array. No
+// elements are copied, the new Array points into the same memory
+func (s Array[T]) Sub(start, end int) Array[T] {
+	if start < 0 || end < start || end > s.len {
+		panic("subarray out of range")
+	}
+
+	return Array[T]{data: s.ptr(start), len: end - start}
+}
+
+// CopyTo copies the elements into dst, which can be in another allocator.
+// The number of elements copied is returned, which is the shorter length
+// of the two arrays
+func (s Array[T]) CopyTo(dst Array[T]) int {
+	return copy(dst.Slice(), s.Slice())
+}
+
+// Copy creates a new Array in the allocator holding the same elements
+func (s Array[T]) Copy(a Allocator) (Ptr[Array[T]], error) {
+	arr, err := NewArray[T](a, s.len)
+	if err != nil {
+		return Ptr[Array[T]]{}, err
+	}
+
+	s.CopyTo(*arr.Deref())
+	return arr, nil
+}
+
+// Concat creates a new Array in the allocator holding the elements of the
+// array followed by the elements of each of others
+func (s Array[T]) Concat(a Allocator, others ...Array[T]) (Ptr[Array[T]], error) {
+	size := s.len
+	for _, other := range others {
+		size += other.len
+	}
+
+	arr, err := NewArray[T](a, size)
+	if err != nil {
+		return Ptr[Array[T]]{}, err
+	}
+
+	dst := *arr.Deref()
+	n := s.CopyTo(dst)
+	for _, other := range others {
+		n += other.CopyTo(dst.Sub(n, size))
+	}
+
+	return arr, nil
+}
+
+// Fill sets every element to val
+func (s Array[T]) Fill(val T) {
+	b := s.Slice()
+	for x := range b {
+		b[x] = val
+	}
+}
+
+// Reverse reverses the order of the elements in place
+func (s Array[T]) Reverse() {
+	slices.Reverse(s.Slice())
+}
+
+// Equal reports if both arrays hold the same elements in the same order
+func Equal[T comparable](a, b Array[T]) bool {
+	return slices.Equal(a.Slice(), b.Slice())
+}
+
+// Index returns the index of the first element equal to val, or -1 if it
+// isn't in the array
+func Index[T comparable](arr Array[T], val T) int {
+	return slices.Index(arr.Slice(), val)
+}
+
+// Contains reports if val is in the array
+func Contains[T comparable](arr Array[T], val T) bool {
+	return Index(arr, val) >= 0
+}
+
 // Expand creates a new Array with the new size specified, Copies the data
 // into the new array, and returns it. The new locations will have uninitialized
 // data in it.
@@ -35,7 +130,7 @@ func (s Array[T]) Expand(size int) (Array[T], error) {
 
 	b, err := NewArray[T](s.data.alloc, size)
 	if err != nil {
-		return Array[T]{}, nil
+		return Array[T]{}, err
 	}
 
 	copy(b.Deref().Slice(), s.Slice())
@@ -2,6 +2,7 @@ package alloc
 
 import (
 	"testing"
+	"unsafe"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -17,3 +18,53 @@ func TestSlice(t *testing.T) {
 
 	assert.Equal(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, s.Deref().Slice())
 }
+
+func TestSliceEmpty(t *testing.T) {
+	assert.Nil(t, Array[int]{}.Slice())
+
+	// an array filling the allocator leaves an empty view at the very end
+	arena := NewPageAllocator()
+	size := arena.Available() - unsafe.Sizeof(Array[byte]{})
+	arr := *Must(NewArray[byte](&arena, int(size))).Deref()
+	assert.Equal(t, uintptr(0), arena.Available())
+	assert.Nil(t, arr.Sub(arr.Length(), arr.Length()).Slice())
+}
+
+func TestArrayOperations(t *testing.T) {
+	arena := NewExpandingAllocator(8)
+	arr := *Must(NewArray[int](&arena, 6)).Deref()
+	for x := range arr.Slice() {
+		arr.Slice()[x] = x
+	}
+
+	// sub arrays share the elements
+	sub := arr.Sub(2, 5)
+	assert.Equal(t, []int{2, 3, 4}, sub.Slice())
+	sub.Fill(7)
+	assert.Equal(t, []int{0, 1, 7, 7, 7, 5}, arr.Slice())
+	assert.Empty(t, arr.Sub(6, 6).Slice())
+	assert.Panics(t, func() { arr.Sub(4, 7) })
+
+	arr.Reverse()
+	assert.Equal(t, []int{5, 7, 7, 7, 1, 0}, arr.Slice())
+
+	assert.Equal(t, 1, Index(arr, 7))
+	assert.Equal(t, -1, Index(arr, 3))
+	assert.True(t, Contains(arr, 0))
+	assert.False(t, Contains(arr.Sub(0, 5), 0))
+
+	// copy into another allocator
+	other := NewPageAllocator()
+	cp := *Must(arr.Copy(&other)).Deref()
+	assert.True(t, Equal(arr, cp))
+	cp.Slice()[0] = 9
+	assert.False(t, Equal(arr, cp))
+	assert.Equal(t, 5, arr.Slice()[0])
+
+	dst := *Must(NewArray[int](&arena, 3)).Deref()
+	assert.Equal(t, 3, arr.CopyTo(dst))
+	assert.Equal(t, []int{5, 7, 7}, dst.Slice())
+
+	joined := *Must(dst.Concat(&other, arr.Sub(4, 6), Array[int]{}, cp.Sub(0, 1))).Deref()
+	assert.Equal(t, []int{5, 7, 7, 1, 0, 9}, joined.Slice())
+}
@@ -0,0 +1,159 @@
+package alloc
+
+import (
+	"iter"
+	"math/bits"
+)
+
+// Bitset is a fixed size set of bits stored in the allocator
+type Bitset struct {
+	words Array[uint64]
+	len   int
+}
+
+// NewBitset creates a bitset in the allocator holding n bits, all cleared.
+// The Bitset is only a header, the bits themselves are in the allocator
+func NewBitset(a Allocator, n int) (Bitset, error) {
+	words, err := NewArray[uint64](a, (n+63)/64)
+	if err != nil {
+		return Bitset{}, err
+	}
+
+	clear(words.Deref().Slice())
+	return Bitset{words: *words.Deref(), len: n}, nil
+}
+
+// Len returns the number of bits in the bitset
+func (b Bitset) Len() int {
+	return b.len
+}
+
+// check panics if i is not a bit in the set
+func (b Bitset) check(i int) {
+	if i < 0 || i >= b.len {
+		panic("bit index out of range")
+	}
+}
+
+// Set sets bit i
+func (b Bitset) Set(i int) {
+	b.check(i)
+	b.words.Slice()[i/64] |= 1 << (i % 64)
+}
+
+// Clear clears bit i
+func (b Bitset) Clear(i int) {
+	b.check(i)
+	b.words.Slice()[i/64] &^= 1 << (i % 64)
+}
+
+// Test returns if bit i is set
+func (b Bitset) Test(i int) bool {
+	b.check(i)
+	return b.words.Slice()[i/64]&(1<<(i%64)) != 0
+}
+
+// ClearAll clears every bit
+func (b Bitset) ClearAll() {
+	clear(b.words.Slice())
+}
+
+// Count returns the number of bits which are set
+func (b Bitset) Count() int {
+	count := 0
+	for _, w := range b.words.Slice() {
+		count += bits.OnesCount64(w)
+	}
+
+	return count
+}
+
+// NextSet returns the index of the first set bit at or after i, false is
+// returned if there are no more set bits
+func (b Bitset) NextSet(i int) (int, bool) {
+	if i < 0 {
+		i = 0
+	}
+
+	if i >= b.len {
+		return 0, false
+	}
+
+	words := b.words.Slice()
+	x := i / 64
+	// ignore the bits before i in the first word
+	w := words[x] >> (i % 64) << (i % 64)
+	for {
+		if w != 0 {
+			return x*64 + bits.TrailingZeros64(w), true
+		}
+
+		x++
+		if x == len(words) {
+			return 0, false
+		}
+
+		w = words[x]
+	}
+}
+
+// All returns an iterator over the index of every set bit
+func (b Bitset) All() iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for i, ok := b.NextSet(0); ok; i, ok = b.NextSet(i + 1) {
+			if !yield(i) {
+				return
+			}
+		}
+	}
+}
+
+// And creates a new bitset in the allocator with the bits set in both
+// bitsets. The result is as long as the longer bitset
+func (b Bitset) And(a Allocator, other Bitset) (Bitset, error) {
+	return b.combine(a, other, func(x, y uint64) uint64 { return x & y })
+}
+
+// Or creates a new bitset in the allocator with the bits set in either
+// bitset
+func (b Bitset) Or(a Allocator, other Bitset) (Bitset, error) {
+	return b.combine(a, other, func(x, y uint64) uint64 { return x | y })
+}
+
+// Xor creates a new bitset in the allocator with the bits set in only one
+// of the bitsets
+func (b Bitset) Xor(a Allocator, other Bitset) (Bitset, error) {
+	return b.combine(a, other, func(x, y uint64) uint64 { return x ^ y })
+}
+
+// AndNot creates a new bitset in the allocator with the bits set in this
+// bitset which are not set in other
+func (b Bitset) AndNot(a Allocator, other Bitset) (Bitset, error) {
+	return b.combine(a, other, func(x, y uint64) uint64 { return x &^ y })
+}
+
+// combine creates a new bitset by applying op to each word. Missing words in
+// the shorter bitset are treated as zero
+func (b Bitset) combine(a Allocator, other Bitset, op func(x, y uint64) uint64) (Bitset, error) {
+	out, err := NewBitset(a, max(b.len, other.len))
+	if err != nil {
+		return Bitset{}, err
+	}
+
+	x, y := b.words.Slice(), other.words.Slice()
+	words := out.words.Slice()
+	for i := range words {
+		var wx, wy uint64
+		if i < len(x) {
+			wx = x[i]
+		}
+
+		if i < len(y) {
+			wy = y[i]
+		}
+
+		words[i] = op(wx, wy)
+	}
+
+	return out, nil
+}
@@ -0,0 +1,64 @@
+package alloc
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBitset(t *testing.T) {
+	arena := NewExpandingAllocator(8)
+	b := Must(NewBitset(&arena, 130))
+
+	assert.Equal(t, 130, b.Len())
+	assert.Equal(t, 0, b.Count())
+
+	for _, i := range []int{0, 63, 64, 129} {
+		b.Set(i)
+	}
+	b.Set(5)
+	b.Clear(5)
+
+	assert.True(t, b.Test(63))
+	assert.False(t, b.Test(5))
+	assert.Equal(t, 4, b.Count())
+	assert.Equal(t, []int{0, 63, 64, 129}, slices.Collect(b.All()))
+
+	i, ok := b.NextSet(1)
+	assert.Equal(t, 63, i)
+	assert.True(t, ok)
+	i, _ = b.NextSet(65)
+	assert.Equal(t, 129, i)
+	_, ok = b.NextSet(130)
+	assert.False(t, ok)
+
+	assert.Panics(t, func() { b.Set(130) })
+	assert.Panics(t, func() { b.Test(-1) })
+
+	b.ClearAll()
+	assert.Equal(t, 0, b.Count())
+}
+
+func TestBitsetOperations(t *testing.T) {
+	arena := NewExpandingAllocator(8)
+	newBitset := func(n int, set ...int) Bitset {
+		b := Must(NewBitset(&arena, n))
+		for _, i := range set {
+			b.Set(i)
+		}
+
+		return b
+	}
+
+	x := newBitset(100, 1, 2, 70)
+	y := newBitset(200, 2, 3, 150)
+
+	and := Must(x.And(&arena, y))
+	assert.Equal(t, 200, and.Len())
+	assert.Equal(t, []int{2}, slices.Collect(and.All()))
+	assert.Equal(t, []int{1, 2, 3, 70, 150}, slices.Collect(Must(x.Or(&arena, y)).All()))
+	assert.Equal(t, []int{1, 3, 70, 150}, slices.Collect(Must(x.Xor(&arena, y)).All()))
+	assert.Equal(t, []int{1, 70}, slices.Collect(Must(x.AndNot(&arena, y)).All()))
+	assert.Equal(t, []int{3, 150}, slices.Collect(Must(y.AndNot(&arena, x)).All()))
+}
@@ -0,0 +1,480 @@
+package alloc
+
+import "iter"
+
+// btreeDegree is the minimum degree of the tree. Every node other than the
+// root holds between btreeDegree-1 and 2*btreeDegree-1 keys
+const btreeDegree = 8
+
+const btreeMaxKeys = 2*btreeDegree - 1
+
+// btreeNode is a single node in the tree, stored in the allocator. Only the
+// first n keys, values and n+1 children (when not a leaf) are in use
+type btreeNode[K Comparable[K], V any] struct {
+	n        int
+	leaf     bool
+	keys     [btreeMaxKeys]K
+	vals     [btreeMaxKeys]V
+	children [btreeMaxKeys + 1]Ptr[btreeNode[K, V]]
+}
+
+// search returns the index of the first key which is not less than key,
+// and if that key is equal to key
+func (n *btreeNode[K, V]) search(key K) (int, bool) {
+	lo, hi := 0, n.n
+	for lo < hi {
+		mid := int(uint(lo+hi) >> 1)
+		if n.keys[mid].Cmp(key) < 0 {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+
+	return lo, lo < n.n && n.keys[lo].Cmp(key) == 0
+}
+
+// BTree is an ordered map whose nodes are stored in the allocator. Keys
+// must implement Comparable, like String does. Use the primitive types such
+// as Int or Float64 for go numbers.
+//
+//	tree := alloc.Must(alloc.NewBTree[alloc.Int, string](&arena))
+//	tree.Set(2, "two")
+//	tree.Set(1, "one")
+//	for k, v := range tree.All() {
+//	  // 1 one, 2 two
+//	}
+//
+// The BTree is a header pointing to the root, which changes as the tree
+// grows.
+type BTree[K Comparable[K], V any] struct {
+	root Ptr[btreeNode[K, V]]
+	len  int
+}
+
+// NewBTree creates a new empty tree in the allocator
+func NewBTree[K Comparable[K], V any](a Allocator) (BTree[K, V], error) {
+	root, err := newBTreeNode[K, V](a, true)
+	if err != nil {
+		return BTree[K, V]{}, err
+	}
+
+	return BTree[K, V]{root: root}, nil
+}
+
+// newBTreeNode creates an empty node in the allocator
+func newBTreeNode[K Comparable[K], V any](a Allocator, leaf bool) (Ptr[btreeNode[K, V]], error) {
+	n, err := New[btreeNode[K, V]](a)
+	if err != nil {
+		return Ptr[btreeNode[K, V]]{}, err
+	}
+
+	n.Set(btreeNode[K, V]{leaf: leaf})
+	return n, nil
+}
+
+// Len returns the number of entries in the tree
+func (t *BTree[K, V]) Len() int {
+	return t.len
+}
+
+// Get returns the value stored for key, if there is no value the empty
+// value of V and false are returned
+func (t *BTree[K, V]) Get(key K) (V, bool) {
+	for x := t.root; ; {
+		n := x.Deref()
+		i, found := n.search(key)
+		if found {
+			return n.vals[i], true
+		}
+
+		if n.leaf {
+			return *new(V), false
+		}
+
+		x = n.children[i]
+	}
+}
+
+// Has returns if there is a value stored for key
+func (t *BTree[K, V]) Has(key K) bool {
+	_, ok := t.Get(key)
+	return ok
+}
+
+// Set stores val under key, replacing any existing value. Allocation errors
+// are returned when a node has to be split to make room
+func (t *BTree[K, V]) Set(key K, val V) error {
+	if t.root.Deref().n == btreeMaxKeys {
+		// the root is full, so it gets split under a new root
+		root, err := newBTreeNode[K, V](t.root.alloc, false)
+		if err != nil {
+			return err
+		}
+
+		root.Deref().children[0] = t.root
+		if err := t.split(root, 0); err != nil {
+			return err
+		}
+
+		t.root = root
+	}
+
+	added, err := t.insert(t.root, key, val)
+	if added {
+		t.len++
+	}
+
+	return err
+}
+
+// split moves the upper half of the full child i of x into a new node,
+// and moves the middle key up into x. x must not be full
+func (t *BTree[K, V]) split(x Ptr[btreeNode[K, V]], i int) error {
+	yp := x.Deref().children[i]
+	zp, err := newBTreeNode[K, V](x.alloc, yp.Deref().leaf)
+	if err != nil {
+		return err
+	}
+
+	// deref after allocating, since the allocation could move the nodes
+	n, y, z := x.Deref(), yp.Deref(), zp.Deref()
+
+	z.n = btreeDegree - 1
+	copy(z.keys[:], y.keys[btreeDegree:])
+	copy(z.vals[:], y.vals[btreeDegree:])
+	if !y.leaf {
+		copy(z.children[:], y.children[btreeDegree:])
+	}
+	y.n = btreeDegree - 1
+
+	copy(n.children[i+2:n.n+2], n.children[i+1:n.n+1])
+	copy(n.keys[i+1:n.n+1], n.keys[i:n.n])
+	copy(n.vals[i+1:n.n+1], n.vals[i:n.n])
+	n.children[i+1] = zp
+	n.keys[i] = y.keys[btreeDegree-1]
+	n.vals[i] = y.vals[btreeDegree-1]
+	n.n++
+
+	return nil
+}
+
+// insert stores the value in the subtree under x which must not be full,
+// splitting full nodes on the way down. It returns true if the key is new
+func (t *BTree[K, V]) insert(x Ptr[btreeNode[K, V]], key K, val V) (bool, error) {
+	for {
+		n := x.Deref()
+		i, found := n.search(key)
+		if found {
+			n.vals[i] = val
+			return false, nil
+		}
+
+		if n.leaf {
+			copy(n.keys[i+1:n.n+1], n.keys[i:n.n])
+			copy(n.vals[i+1:n.n+1], n.vals[i:n.n])
+			n.keys[i] = key
+			n.vals[i] = val
+			n.n++
+			return true, nil
+		}
+
+		if n.children[i].Deref().n == btreeMaxKeys {
+			if err := t.split(x, i); err != nil {
+				return false, err
+			}
+
+			// the middle key of the child is now at i, so we need to
+			// decide which half to go down
+			n = x.Deref()
+			switch c := key.Cmp(n.keys[i]); {
+			case c == 0:
+				n.vals[i] = val
+				return false, nil
+			case c > 0:
+				i++
+			}
+		}
+
+		x = n.children[i]
+	}
+}
+
+// Delete removes key from the tree, returning the value which was stored
+// and true, or false if the key wasn't in the tree
+func (t *BTree[K, V]) Delete(key K) (V, bool) {
+	val, ok := t.delete(t.root, key)
+	if ok {
+		t.len--
+	}
+
+	// the root can be left empty after merging its only two children
+	if root := t.root.Deref(); root.n == 0 && !root.leaf {
+		t.root = root.children[0]
+	}
+
+	return val, ok
+}
+
+// delete removes key from the subtree under x. Every node we go down into
+// has at least btreeDegree keys, so removing a key never leaves a node
+// with too few
+func (t *BTree[K, V]) delete(x Ptr[btreeNode[K, V]], key K) (V, bool) {
+	n := x.Deref()
+	i, found := n.search(key)
+
+	if found {
+		val := n.vals[i]
+		if n.leaf {
+			copy(n.keys[i:], n.keys[i+1:n.n])
+			copy(n.vals[i:], n.vals[i+1:n.n])
+			n.n--
+			return val, true
+		}
+
+		// replace the key with its predecessor or successor, then remove
+		// that from the child instead
+		y, z := n.children[i], n.children[i+1]
+		switch {
+		case y.Deref().n >= btreeDegree:
+			k, v := t.max(y)
+			n.keys[i], n.vals[i] = k, v
+			t.delete(y, k)
+		case z.Deref().n >= btreeDegree:
+			k, v := t.min(z)
+			n.keys[i], n.vals[i] = k, v
+			t.delete(z, k)
+		default:
+			t.merge(x, i)
+			t.delete(y, key)
+		}
+
+		return val, true
+	}
+
+	if n.leaf {
+		return *new(V), false
+	}
+
+	if n.children[i].Deref().n < btreeDegree {
+		switch {
+		case i > 0 && n.children[i-1].Deref().n >= btreeDegree:
+			t.borrowLeft(x, i)
+		case i < n.n && n.children[i+1].Deref().n >= btreeDegree:
+			t.borrowRight(x, i)
+		case i < n.n:
+			t.merge(x, i)
+		default:
+			t.merge(x, i-1)
+			i--
+		}
+	}
+
+	return t.delete(n.children[i], key)
+}
+
+// merge combines child i, key i and child i+1 of x into child i
+func (t *BTree[K, V]) merge(x Ptr[btreeNode[K, V]], i int) {
+	n := x.Deref()
+	y, z := n.children[i].Deref(), n.children[i+1].Deref()
+
+	y.keys[y.n] = n.keys[i]
+	y.vals[y.n] = n.vals[i]
+	copy(y.keys[y.n+1:], z.keys[:z.n])
+	copy(y.vals[y.n+1:], z.vals[:z.n])
+	if !y.leaf {
+		copy(y.children[y.n+1:], z.children[:z.n+1])
+	}
+	y.n += z.n + 1
+
+	copy(n.keys[i:], n.keys[i+1:n.n])
+	copy(n.vals[i:], n.vals[i+1:n.n])
+	copy(n.children[i+1:], n.children[i+2:n.n+1])
+	n.n--
+}
+
+// borrowLeft moves a key from the left sibling of child i, through x, into
+// child i
+func (t *BTree[K, V]) borrowLeft(x Ptr[btreeNode[K, V]], i int) {
+	n := x.Deref()
+	c, s := n.children[i].Deref(), n.children[i-1].Deref()
+
+	copy(c.keys[1:c.n+1], c.keys[:c.n])
+	copy(c.vals[1:c.n+1], c.vals[:c.n])
+	if !c.leaf {
+		copy(c.children[1:c.n+2], c.children[:c.n+1])
+		c.children[0] = s.children[s.n]
+	}
+
+	c.keys[0], c.vals[0] = n.keys[i-1], n.vals[i-1]
+	n.keys[i-1], n.vals[i-1] = s.keys[s.n-1], s.vals[s.n-1]
+	c.n++
+	s.n--
+}
+
+// borrowRight moves a key from the right sibling of child i, through x,
+// into child i
+func (t *BTree[K, V]) borrowRight(x Ptr[btreeNode[K, V]], i int) {
+	n := x.Deref()
+	c, s := n.children[i].Deref(), n.children[i+1].Deref()
+
+	c.keys[c.n], c.vals[c.n] = n.keys[i], n.vals[i]
+	if !c.leaf {
+		c.children[c.n+1] = s.children[0]
+		copy(s.children[:], s.children[1:s.n+1])
+	}
+
+	n.keys[i], n.vals[i] = s.keys[0], s.vals[0]
+	copy(s.keys[:], s.keys[1:s.n])
+	copy(s.vals[:], s.vals[1:s.n])
+	c.n++
+	s.n--
+}
+
+// min returns the smallest entry in the subtree under x
+func (t *BTree[K, V]) min(x Ptr[btreeNode[K, V]]) (K, V) {
+	n := x.Deref()
+	for !n.leaf {
+		n = n.children[0].Deref()
+	}
+
+	return n.keys[0], n.vals[0]
+}
+
+// max returns the largest entry in the subtree under x
+func (t *BTree[K, V]) max(x Ptr[btreeNode[K, V]]) (K, V) {
+	n := x.Deref()
+	for !n.leaf {
+		n = n.children[n.n].Deref()
+	}
+
+	return n.keys[n.n-1], n.vals[n.n-1]
+}
+
+// Min returns the entry with the smallest key, false is returned if the
+// tree is empty
+func (t *BTree[K, V]) Min() (K, V, bool) {
+	if t.len == 0 {
+		return *new(K), *new(V), false
+	}
+
+	k, v := t.min(t.root)
+	return k, v, true
+}
+
+// Max returns the entry with the largest key, false is returned if the
+// tree is empty
+func (t *BTree[K, V]) Max() (K, V, bool) {
+	if t.len == 0 {
+		return *new(K), *new(V), false
+	}
+
+	k, v := t.max(t.root)
+	return k, v, true
+}
+
+// Floor returns the entry with the largest key less than or equal to key,
+// false is returned if there isn't one
+func (t *BTree[K, V]) Floor(key K) (K, V, bool) {
+	var k K
+	var v V
+	var ok bool
+
+	for x := t.root; ; {
+		n := x.Deref()
+		i, found := n.search(key)
+		if found {
+			return n.keys[i], n.vals[i], true
+		}
+
+		if i > 0 {
+			k, v, ok = n.keys[i-1], n.vals[i-1], true
+		}
+
+		if n.leaf {
+			return k, v, ok
+		}
+
+		x = n.children[i]
+	}
+}
+
+// Ceiling returns the entry with the smallest key greater than or equal
+// to key, false is returned if there isn't one
+func (t *BTree[K, V]) Ceiling(key K) (K, V, bool) {
+	var k K
+	var v V
+	var ok bool
+
+	for x := t.root; ; {
+		n := x.Deref()
+		i, found := n.search(key)
+		if found {
+			return n.keys[i], n.vals[i], true
+		}
+
+		if i < n.n {
+			k, v, ok = n.keys[i], n.vals[i], true
+		}
+
+		if n.leaf {
+			return k, v, ok
+		}
+
+		x = n.children[i]
+	}
+}
+
+// btreeBounds limits which keys are visited by walk
+type btreeBounds[K any] struct {
+	lo, hi       K
+	hasLo, hasHi bool
+}
+
+// All returns an iterator over every entry in key order
+func (t *BTree[K, V]) All() iter.Seq2[K, V] {
+	root := t.root
+	return func(yield func(K, V) bool) {
+		t.walk(root, btreeBounds[K]{}, yield)
+	}
+}
+
+// Range returns an iterator over the entries with keys from lo up to, but
+// not including, hi in key order
+func (t *BTree[K, V]) Range(lo, hi K) iter.Seq2[K, V] {
+	root := t.root
+	return func(yield func(K, V) bool) {
+		t.walk(root, btreeBounds[K]{lo: lo, hi: hi, hasLo: true, hasHi: true}, yield)
+	}
+}
+
+// walk visits the entries in the subtree under x within the bounds in
+// order. false is returned when iteration should stop
+func (t *BTree[K, V]) walk(x Ptr[btreeNode[K, V]], b btreeBounds[K], yield func(K, V) bool) bool {
+	i := 0
+	if b.hasLo {
+		i, _ = x.Deref().search(b.lo)
+	}
+
+	// we deref on every access since yield could cause the nodes to move
+	for ; i <= x.Deref().n; i++ {
+		if n := x.Deref(); !n.leaf && !t.walk(n.children[i], b, yield) {
+			return false
+		}
+
+		n := x.Deref()
+		if i == n.n {
+			break
+		}
+
+		if b.hasHi && n.keys[i].Cmp(b.hi) >= 0 {
+			return false
+		}
+
+		if !yield(n.keys[i], n.vals[i]) {
+			return false
+		}
+	}
+
+	return true
+}
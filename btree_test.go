@@ -0,0 +1,123 @@
+package alloc
+
+import (
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// btreeKeys collects the keys from the iterator
+func btreeKeys[K Comparable[K], V any](seq func(func(K, V) bool)) []K {
+	var keys []K
+	for k := range seq {
+		keys = append(keys, k)
+	}
+
+	return keys
+}
+
+func TestBTree(t *testing.T) {
+	arena := NewExpandingAllocator(8)
+	tree := Must(NewBTree[Int, int](&arena))
+
+	_, _, ok := tree.Min()
+	assert.False(t, ok)
+
+	// insert and delete in a random order, checking against a map
+	rng := rand.New(rand.NewPCG(1, 2))
+	expected := map[int]int{}
+	for range 5000 {
+		k := rng.IntN(1000)
+		if rng.IntN(3) == 0 {
+			v, ok := tree.Delete(Int(k))
+			ev, eok := expected[k]
+			assert.Equal(t, eok, ok)
+			assert.Equal(t, ev, v)
+			delete(expected, k)
+			continue
+		}
+
+		assert.NoError(t, tree.Set(Int(k), k*10))
+		expected[k] = k * 10
+	}
+
+	assert.Equal(t, len(expected), tree.Len())
+
+	var want []Int
+	for k := range expected {
+		want = append(want, Int(k))
+	}
+	slices.Sort(want)
+	assert.Equal(t, want, btreeKeys(tree.All()))
+
+	for k, v := range expected {
+		got, ok := tree.Get(Int(k))
+		assert.True(t, ok)
+		assert.Equal(t, v, got)
+	}
+
+	// delete everything that's left
+	for k := range expected {
+		_, ok := tree.Delete(Int(k))
+		assert.True(t, ok)
+	}
+
+	assert.Equal(t, 0, tree.Len())
+	assert.Empty(t, btreeKeys(tree.All()))
+}
+
+func TestBTreeQueries(t *testing.T) {
+	arena := NewExpandingAllocator(8)
+	tree := Must(NewBTree[Int, string](&arena))
+	for x := 0; x < 100; x += 10 {
+		tree.Set(Int(x), strconv.Itoa(x))
+	}
+
+	k, v, ok := tree.Min()
+	assert.Equal(t, Int(0), k)
+	assert.Equal(t, "0", v)
+	assert.True(t, ok)
+
+	k, _, _ = tree.Max()
+	assert.Equal(t, Int(90), k)
+
+	k, _, ok = tree.Floor(25)
+	assert.Equal(t, Int(20), k)
+	assert.True(t, ok)
+	k, _, _ = tree.Floor(30)
+	assert.Equal(t, Int(30), k)
+	_, _, ok = tree.Floor(-1)
+	assert.False(t, ok)
+
+	k, _, ok = tree.Ceiling(25)
+	assert.Equal(t, Int(30), k)
+	assert.True(t, ok)
+	_, _, ok = tree.Ceiling(91)
+	assert.False(t, ok)
+
+	assert.Equal(t, []Int{20, 30, 40}, btreeKeys(tree.Range(15, 50)))
+	assert.Equal(t, []Int{20}, btreeKeys(tree.Range(20, 30)))
+	assert.Empty(t, btreeKeys(tree.Range(91, 100)))
+}
+
+func TestBTreeString(t *testing.T) {
+	arena := NewExpandingAllocator(8)
+	tree := Must(NewBTree[String, int](&arena))
+	for _, s := range []string{"pear", "apple", "fig"} {
+		tree.Set(*Must(NewString(&arena, s)).Deref(), len(s))
+	}
+
+	var keys []string
+	for k := range tree.All() {
+		keys = append(keys, k.Cast())
+	}
+
+	assert.Equal(t, []string{"apple", "fig", "pear"}, keys)
+
+	v, ok := tree.Get(*Must(NewString(&arena, "fig")).Deref())
+	assert.True(t, ok)
+	assert.Equal(t, 3, v)
+}
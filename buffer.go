@@ -0,0 +1,223 @@
+package alloc
+
+import (
+	"io"
+	"unicode/utf8"
+	"unsafe"
+)
+
+// Buffer is a growable byte buffer whose bytes are stored in the allocator.
+// It implements io.Writer, io.ByteWriter, io.StringWriter and io.ReaderFrom
+// so it can be used to build up strings without going through the heap.
+//
+// When the buffer is the last thing allocated it grows in place, so writing
+// into a single buffer never copies. If something else was allocated after
+// the buffer it is moved to a new location twice the size.
+//
+// The Buffer itself is a small header which you hold onto, only the bytes
+// are stored in the allocator. This is because writing can move the bytes,
+// and the header needs to be updated when it does.
+//
+// A zero Buffer has no allocator to write into and panics when it grows,
+// create buffers with NewBuffer.
+type Buffer struct {
+	data Ptr[byte]
+	len  int
+	cap  int
+}
+
+// ensure we implement the writer interfaces
+var (
+	_ io.Writer       = &Buffer{}
+	_ io.ByteWriter   = &Buffer{}
+	_ io.StringWriter = &Buffer{}
+	_ io.ReaderFrom   = &Buffer{}
+)
+
+// NewBuffer creates a buffer in the allocator with room for size bytes
+func NewBuffer(a Allocator, size int) (Buffer, error) {
+	offset, err := a.Alloc(uintptr(size), 1)
+	if err != nil {
+		return Buffer{}, err
+	}
+
+	return Buffer{
+		data: Ptr[byte]{offset: offset, alloc: a},
+		cap:  size,
+	}, nil
+}
+
+// Len returns the number of bytes written to the buffer
+func (b *Buffer) Len() int {
+	return b.len
+}
+
+// Cap returns the number of bytes the buffer can hold before it grows
+func (b *Buffer) Cap() int {
+	return b.cap
+}
+
+// Bytes returns the bytes written to the buffer. The slice points into the
+// allocator, so it is only valid until the next write
+func (b *Buffer) Bytes() []byte {
+	return b.all()[:b.len]
+}
+
+// Str returns the bytes written to the buffer as a String without copying.
+// The String shares the bytes with the buffer, so you can keep writing
+// but you must not Reset the buffer while the String is in use
+func (b *Buffer) Str() String {
+	return String(Array[byte]{data: b.data, len: b.len})
+}
+
+// NewString places a String holding the bytes written to the buffer into
+// the allocator, without copying the bytes. Like Str, the String shares the
+// bytes with the buffer, so you must not Reset the buffer while the String
+// is in use
+func (b *Buffer) NewString() (Ptr[String], error) {
+	s, err := New[String](b.data.alloc)
+	if err != nil {
+		return Ptr[String]{}, err
+	}
+
+	s.Set(b.Str())
+	return s, nil
+}
+
+// String returns a copy of the bytes as a golang string
+func (b *Buffer) String() string {
+	return string(b.Bytes())
+}
+
+// Reset empties the buffer, keeping the memory for future writes
+func (b *Buffer) Reset() {
+	b.len = 0
+}
+
+// Truncate discards everything but the first n bytes
+func (b *Buffer) Truncate(n int) {
+	if n < 0 || n > b.len {
+		panic("truncation out of range")
+	}
+
+	b.len = n
+}
+
+// all returns the entire capacity of the buffer as a slice
+func (b *Buffer) all() []byte {
+	if b.cap == 0 {
+		return nil
+	}
+
+	return unsafe.Slice(b.data.Deref(), b.cap)
+}
+
+// Grow makes sure there is space to write another n bytes without another
+// allocation. Allocation errors are returned if the buffer can't grow
+func (b *Buffer) Grow(n int) error {
+	if b.cap-b.len >= n {
+		return nil
+	}
+
+	a := b.data.alloc
+	need := b.len + n
+	size := max(b.cap*2, need)
+
+	// allocating nothing tells us where the next allocation starts, see
+	// Allocator. If that is the end of the buffer we can grow in place
+	head, err := a.Alloc(0, 1)
+	if err != nil {
+		return err
+	}
+
+	if head == b.data.offset+uintptr(b.cap) {
+		if _, err := a.Alloc(uintptr(size-b.cap), 1); err != nil {
+			// doubling may not fit, so try for just what we need
+			size = need
+			if _, err := a.Alloc(uintptr(size-b.cap), 1); err != nil {
+				return err
+			}
+		}
+
+		b.cap = size
+		return nil
+	}
+
+	offset, err := a.Alloc(uintptr(size), 1)
+	if err != nil {
+		size = need
+		if offset, err = a.Alloc(uintptr(size), 1); err != nil {
+			return err
+		}
+	}
+
+	moved := Buffer{data: Ptr[byte]{offset: offset, alloc: a}, len: b.len, cap: size}
+	copy(moved.all(), b.Bytes())
+	*b = moved
+	return nil
+}
+
+// Write appends p to the buffer
+func (b *Buffer) Write(p []byte) (int, error) {
+	if err := b.Grow(len(p)); err != nil {
+		return 0, err
+	}
+
+	b.len += copy(b.all()[b.len:], p)
+	return len(p), nil
+}
+
+// WriteString appends s to the buffer
+func (b *Buffer) WriteString(s string) (int, error) {
+	if err := b.Grow(len(s)); err != nil {
+		return 0, err
+	}
+
+	b.len += copy(b.all()[b.len:], s)
+	return len(s), nil
+}
+
+// WriteByte appends c to the buffer
+func (b *Buffer) WriteByte(c byte) error {
+	if err := b.Grow(1); err != nil {
+		return err
+	}
+
+	b.all()[b.len] = c
+	b.len++
+	return nil
+}
+
+// WriteRune appends the UTF-8 encoding of r to the buffer
+func (b *Buffer) WriteRune(r rune) (int, error) {
+	if err := b.Grow(utf8.UTFMax); err != nil {
+		return 0, err
+	}
+
+	n := utf8.EncodeRune(b.all()[b.len:], r)
+	b.len += n
+	return n, nil
+}
+
+// minRead is the space made available for each read in ReadFrom
+const minRead = 512
+
+// ReadFrom reads from r until io.EOF, appending everything to the buffer
+func (b *Buffer) ReadFrom(r io.Reader) (int64, error) {
+	var total int64
+	for {
+		if err := b.Grow(minRead); err != nil {
+			return total, err
+		}
+
+		n, err := r.Read(b.all()[b.len:])
+		b.len += n
+		total += int64(n)
+
+		if err == io.EOF {
+			return total, nil
+		} else if err != nil {
+			return total, err
+		}
+	}
+}
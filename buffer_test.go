@@ -0,0 +1,81 @@
+package alloc
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuffer(t *testing.T) {
+	arena := NewExpandingAllocator(8)
+	b, err := NewBuffer(&arena, 0)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	b.WriteString("hello")
+	b.WriteByte(',')
+	b.WriteRune('🙂')
+	b.Write([]byte(" world"))
+	fmt.Fprintf(&b, " %d", 42)
+
+	assert.Equal(t, "hello,🙂 world 42", b.String())
+	assert.Equal(t, "hello,🙂 world 42", b.Str().Cast())
+
+	b.Truncate(5)
+	assert.Equal(t, "hello", b.String())
+
+	// the string is placed in the allocator without copying the bytes
+	s, err := b.NewString()
+	if assert.NoError(t, err) {
+		assert.Equal(t, "hello", s.Deref().Cast())
+		assert.Equal(t, b.data.offset, s.Deref().data.offset)
+	}
+
+	b.Reset()
+	n, err := b.ReadFrom(strings.NewReader(strings.Repeat("a", 2000)))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2000), n)
+	assert.Equal(t, strings.Repeat("a", 2000), b.String())
+}
+
+func TestBufferGrowInPlace(t *testing.T) {
+	arena := NewPageAllocator()
+	b, err := NewBuffer(&arena, 8)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// nothing else is allocated so the buffer grows in place
+	start := b.data.offset
+	b.WriteString(strings.Repeat("a", 100))
+	assert.Equal(t, start, b.data.offset)
+	s := b.Str()
+
+	// once something else is allocated the buffer has to move, but the
+	// string we took before is still valid
+	_, err = New[uint64](&arena)
+	assert.NoError(t, err)
+	b.WriteString(strings.Repeat("b", 100))
+	assert.NotEqual(t, start, b.data.offset)
+	assert.Equal(t, strings.Repeat("a", 100)+strings.Repeat("b", 100), b.String())
+	assert.Equal(t, strings.Repeat("a", 100), s.Cast())
+}
+
+func TestBufferExhausted(t *testing.T) {
+	arena := NewPageAllocator()
+	b, err := NewBuffer(&arena, 0)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// the buffer can use the whole page, even though it can't double
+	_, err = b.WriteString(strings.Repeat("a", pageSize))
+	assert.NoError(t, err)
+
+	err = b.WriteByte('a')
+	assert.ErrorIs(t, err, ErrMemoryExhausted)
+	assert.Equal(t, pageSize, b.Len())
+}
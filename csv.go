@@ -0,0 +1,246 @@
+package alloc
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"unsafe"
+)
+
+var ErrInvalidCSV = errors.New("invalid csv")
+
+// CSVReader reads records from a CSV file (RFC 4180) into the allocator.
+// Each record is returned as an Array[String]. Quoted fields can contain the
+// delimiter, newlines and escaped ("") quotes. Empty lines are skipped.
+//
+// The reader keeps a few buffers which it reuses between records, so the only
+// allocations per record are made in the allocator. To keep memory from
+// growing over large files set ResetEvery, which resets the allocator before
+// reading the next batch of records.
+type CSVReader struct {
+	// Comma is the field delimiter, it defaults to ','. Setting it to '\t'
+	// will read tab separated files
+	Comma byte
+
+	// ResetEvery resets the allocator after every n records if it is a
+	// ResetAllocator. Setting it to 1 reuses the same memory for every row.
+	// Records read before the reset must no longer be used. 0 never resets
+	ResetEvery int
+
+	a     Allocator
+	r     *bufio.Reader
+	line  int
+	count int
+	err   error
+
+	// lineBuf joins the pieces of a line which is longer than the
+	// bufio.Reader's buffer, so it can be returned as one slice
+	lineBuf []byte
+	// fields holds the unquoted bytes of every field in the record
+	fields []byte
+	// ends holds the end of each field within fields
+	ends []int
+}
+
+// NewCSVReader creates a reader which reads records from r into a
+func NewCSVReader(a Allocator, r io.Reader) *CSVReader {
+	return &CSVReader{
+		Comma: ',',
+		a:     a,
+		r:     bufio.NewReader(r),
+	}
+}
+
+// Read reads the next record. io.EOF is returned when there are no more
+// records, and ErrInvalidCSV if the record can't be parsed
+func (r *CSVReader) Read() (Array[String], error) {
+	if r.ResetEvery > 0 && r.count > 0 && r.count%r.ResetEvery == 0 {
+		if a, ok := r.a.(ResetAllocator); ok {
+			a.Reset()
+		}
+	}
+
+	if err := r.readRecord(); err != nil {
+		return Array[String]{}, err
+	}
+
+	// all the fields are placed next to each other, and the strings point
+	// into them
+	data, err := r.a.Alloc(uintptr(len(r.fields)), 1)
+	if err != nil {
+		return Array[String]{}, err
+	}
+
+	if len(r.fields) > 0 {
+		copy(unsafe.Slice((*byte)(r.a.Offset(data)), len(r.fields)), r.fields)
+	}
+
+	arr, err := NewArray[String](r.a, len(r.ends))
+	if err != nil {
+		return Array[String]{}, err
+	}
+
+	record := *arr.Deref()
+	start := 0
+	for x, end := range r.ends {
+		record.Slice()[x] = String(Array[byte]{
+			data: Ptr[byte]{offset: data + uintptr(start), alloc: r.a},
+			len:  end - start,
+		})
+		start = end
+	}
+
+	r.count++
+	return record, nil
+}
+
+// Records returns an iterator over the remaining records and their index.
+// Iteration stops at the end of the input or the first error, which can be
+// checked with Err
+func (r *CSVReader) Records() iter.Seq2[int, Array[String]] {
+	return func(yield func(int, Array[String]) bool) {
+		for {
+			index := r.count
+			record, err := r.Read()
+			if err != nil {
+				if err != io.EOF {
+					r.err = err
+				}
+
+				return
+			}
+
+			if !yield(index, record) {
+				return
+			}
+		}
+	}
+}
+
+// Err returns the error which stopped Records
+func (r *CSVReader) Err() error {
+	return r.err
+}
+
+// Line returns the line number of the last line read
+func (r *CSVReader) Line() int {
+	return r.line
+}
+
+func (r *CSVReader) errorf(format string, args ...any) error {
+	return fmt.Errorf("%w: line %d: %s", ErrInvalidCSV, r.line, fmt.Sprintf(format, args...))
+}
+
+// readRecord parses the next record into fields and ends
+func (r *CSVReader) readRecord() error {
+	r.fields = r.fields[:0]
+	r.ends = r.ends[:0]
+
+	var line []byte
+	for len(line) == 0 {
+		var err error
+		line, err = r.readLine()
+		if err != nil {
+			return err
+		}
+	}
+
+	for pos := 0; ; {
+		if pos < len(line) && line[pos] == '"' {
+			pos++
+			for {
+				x := bytes.IndexByte(line[pos:], '"')
+				if x == -1 {
+					// the quoted field continues on the next line
+					r.fields = append(r.fields, line[pos:]...)
+					r.fields = append(r.fields, '\n')
+
+					var err error
+					line, err = r.readLine()
+					if err == io.EOF {
+						return r.errorf("unterminated quoted field")
+					} else if err != nil {
+						return err
+					}
+
+					pos = 0
+					continue
+				}
+
+				r.fields = append(r.fields, line[pos:pos+x]...)
+				pos += x + 1
+
+				// "" is an escaped quote
+				if pos < len(line) && line[pos] == '"' {
+					r.fields = append(r.fields, '"')
+					pos++
+					continue
+				}
+
+				break
+			}
+
+			r.ends = append(r.ends, len(r.fields))
+			if pos == len(line) {
+				return nil
+			}
+
+			if line[pos] != r.Comma {
+				return r.errorf("unexpected %q after quoted field", line[pos])
+			}
+
+			pos++
+			continue
+		}
+
+		field := line[pos:]
+		end := bytes.IndexByte(field, r.Comma)
+		if end != -1 {
+			field = field[:end]
+		}
+
+		if bytes.IndexByte(field, '"') != -1 {
+			return r.errorf("bare quote in unquoted field")
+		}
+
+		r.fields = append(r.fields, field...)
+		r.ends = append(r.ends, len(r.fields))
+		if end == -1 {
+			return nil
+		}
+
+		pos += end + 1
+	}
+}
+
+// readLine returns the next line without the line ending. io.EOF is
+// returned when there are no lines left
+func (r *CSVReader) readLine() ([]byte, error) {
+	line, err := r.r.ReadSlice('\n')
+	if err == bufio.ErrBufferFull {
+		r.lineBuf = append(r.lineBuf[:0], line...)
+		for err == bufio.ErrBufferFull {
+			line, err = r.r.ReadSlice('\n')
+			r.lineBuf = append(r.lineBuf, line...)
+		}
+
+		line = r.lineBuf
+	}
+
+	// the last line does not need a line ending
+	if err == io.EOF && len(line) > 0 {
+		err = nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	r.line++
+	line = bytes.TrimSuffix(line, []byte{'\n'})
+	line = bytes.TrimSuffix(line, []byte{'\r'})
+	return line, nil
+}
@@ -0,0 +1,92 @@
+package alloc
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// csvStrings turns the record into go strings so it can be compared
+func csvStrings(record Array[String]) []string {
+	out := make([]string, 0, record.Length())
+	for s := range record.Iter() {
+		out = append(out, s.String())
+	}
+
+	return out
+}
+
+func TestCSVReader(t *testing.T) {
+	in := "name,quote\r\n" +
+		"alice,\"hello, world\"\r\n" +
+		"\n" +
+		"bob,\"she said \"\"hi\"\"\nand left\"\n" +
+		",\"\"\n" +
+		"carol,last"
+
+	arena := NewExpandingAllocator(8)
+	r := NewCSVReader(&arena, strings.NewReader(in))
+
+	var records [][]string
+	for x, record := range r.Records() {
+		assert.Equal(t, len(records), x)
+		records = append(records, csvStrings(record))
+	}
+
+	assert.NoError(t, r.Err())
+	assert.Equal(t, [][]string{
+		{"name", "quote"},
+		{"alice", "hello, world"},
+		{"bob", "she said \"hi\"\nand left"},
+		{"", ""},
+		{"carol", "last"},
+	}, records)
+	assert.Equal(t, 7, r.Line())
+}
+
+func TestCSVReaderComma(t *testing.T) {
+	arena := NewExpandingAllocator(8)
+	r := NewCSVReader(&arena, strings.NewReader("a\tb,c\t\"d\"\n"))
+	r.Comma = '\t'
+
+	record, err := r.Read()
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"a", "b,c", "d"}, csvStrings(record))
+	}
+
+	_, err = r.Read()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestCSVReaderReset(t *testing.T) {
+	arena := NewPageAllocator()
+	r := NewCSVReader(&arena, strings.NewReader(strings.Repeat("a,b,c\n", 1000)))
+	r.ResetEvery = 1
+
+	// the page would run out of memory if it wasn't reset for every row
+	count := 0
+	for _, record := range r.Records() {
+		assert.Equal(t, []string{"a", "b", "c"}, csvStrings(record))
+		count++
+	}
+
+	assert.NoError(t, r.Err())
+	assert.Equal(t, 1000, count)
+}
+
+func TestCSVReaderInvalid(t *testing.T) {
+	for _, in := range []string{
+		"a,b\"c\n",
+		"\"abc\"d\n",
+		"a,\"unterminated\n",
+	} {
+		arena := NewExpandingAllocator(8)
+		r := NewCSVReader(&arena, strings.NewReader(in))
+		for range r.Records() {
+		}
+
+		assert.ErrorIs(t, r.Err(), ErrInvalidCSV, in)
+	}
+}
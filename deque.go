@@ -0,0 +1,231 @@
+package alloc
+
+import "iter"
+
+// dequeMode controls what happens when a push is made to a full deque
+type dequeMode uint8
+
+const (
+	// dequeGrow moves the deque to a larger array in the allocator
+	dequeGrow dequeMode = iota
+	// dequeFixed returns ErrFull
+	dequeFixed
+	// dequeOverwrite drops the value at the opposite end
+	dequeOverwrite
+)
+
+// Deque is a double ended queue stored in a ring buffer in the allocator.
+// Values can be pushed and popped from both ends, and accessed by index
+// where 0 is the front.
+//
+// The Deque is a header you hold onto, the values are stored in the
+// allocator.
+type Deque[T any] struct {
+	buf  Array[T]
+	head int
+	len  int
+	mode dequeMode
+}
+
+// NewDeque creates an empty deque in the allocator with room for size
+// values. When full it grows by doubling, leaving the old values behind
+func NewDeque[T any](a Allocator, size int) (Deque[T], error) {
+	return newDeque[T](a, size, dequeGrow)
+}
+
+// NewBoundedDeque creates an empty deque in the allocator which never holds
+// more than capacity values. When full, pushes return ErrFull unless
+// overwrite is set, in which case the value at the other end is dropped to
+// make room. Pushing to the back of a full overwriting deque drops the front
+// value, making it useful for keeping the most recent values.
+func NewBoundedDeque[T any](a Allocator, capacity int, overwrite bool) (Deque[T], error) {
+	if capacity < 1 {
+		panic("capacity must be at least 1")
+	}
+
+	mode := dequeFixed
+	if overwrite {
+		mode = dequeOverwrite
+	}
+
+	return newDeque[T](a, capacity, mode)
+}
+
+func newDeque[T any](a Allocator, size int, mode dequeMode) (Deque[T], error) {
+	buf, err := NewArray[T](a, size)
+	if err != nil {
+		return Deque[T]{}, err
+	}
+
+	return Deque[T]{buf: *buf.Deref(), mode: mode}, nil
+}
+
+// Len returns the number of values in the deque
+func (d *Deque[T]) Len() int {
+	return d.len
+}
+
+// Cap returns the number of values the deque can hold before it is full
+func (d *Deque[T]) Cap() int {
+	return d.buf.Length()
+}
+
+// index returns the location in the buffer of the value at index i
+func (d *Deque[T]) index(i int) int {
+	i += d.head
+	if i >= d.buf.Length() {
+		i -= d.buf.Length()
+	}
+
+	return i
+}
+
+// makeRoom ensures there is space for one more value. It returns true if
+// the value at the opposite end of the push needs to be dropped
+func (d *Deque[T]) makeRoom() (bool, error) {
+	if d.len < d.buf.Length() {
+		return false, nil
+	}
+
+	switch d.mode {
+	case dequeFixed:
+		return false, ErrFull
+	case dequeOverwrite:
+		return true, nil
+	}
+
+	size := d.buf.Length() * 2
+	if size == 0 {
+		size = 8
+	}
+
+	buf, err := NewArray[T](d.buf.data.alloc, size)
+	if err != nil {
+		return false, err
+	}
+
+	// copy the values over so the front is at the start of the array
+	vals := buf.Deref().Slice()
+	n := copy(vals, d.buf.Slice()[d.head:])
+	copy(vals[n:], d.buf.Slice()[:d.head])
+
+	d.buf = *buf.Deref()
+	d.head = 0
+	return false, nil
+}
+
+// PushBack adds v to the back of the deque
+func (d *Deque[T]) PushBack(v T) error {
+	drop, err := d.makeRoom()
+	if err != nil {
+		return err
+	}
+
+	if drop {
+		d.PopFront()
+	}
+
+	d.buf.Slice()[d.index(d.len)] = v
+	d.len++
+	return nil
+}
+
+// PushFront adds v to the front of the deque
+func (d *Deque[T]) PushFront(v T) error {
+	drop, err := d.makeRoom()
+	if err != nil {
+		return err
+	}
+
+	if drop {
+		d.PopBack()
+	}
+
+	d.head--
+	if d.head < 0 {
+		d.head += d.buf.Length()
+	}
+
+	d.buf.Slice()[d.head] = v
+	d.len++
+	return nil
+}
+
+// PopFront removes and returns the value at the front of the deque, false
+// is returned if the deque is empty
+func (d *Deque[T]) PopFront() (T, bool) {
+	if d.len == 0 {
+		return *new(T), false
+	}
+
+	v := d.buf.Slice()[d.head]
+	d.head = d.index(1)
+	d.len--
+	return v, true
+}
+
+// PopBack removes and returns the value at the back of the deque, false is
+// returned if the deque is empty
+func (d *Deque[T]) PopBack() (T, bool) {
+	if d.len == 0 {
+		return *new(T), false
+	}
+
+	d.len--
+	return d.buf.Slice()[d.index(d.len)], true
+}
+
+// Front returns the value at the front of the deque without removing it
+func (d *Deque[T]) Front() (T, bool) {
+	if d.len == 0 {
+		return *new(T), false
+	}
+
+	return d.At(0), true
+}
+
+// Back returns the value at the back of the deque without removing it
+func (d *Deque[T]) Back() (T, bool) {
+	if d.len == 0 {
+		return *new(T), false
+	}
+
+	return d.At(d.len - 1), true
+}
+
+// At returns the value at index i, where 0 is the front. It panics if i is
+// out of range
+func (d *Deque[T]) At(i int) T {
+	if i < 0 || i >= d.len {
+		panic("deque index out of range")
+	}
+
+	return d.buf.Slice()[d.index(i)]
+}
+
+// Set replaces the value at index i, where 0 is the front. It panics if i
+// is out of range
+func (d *Deque[T]) Set(i int, v T) {
+	if i < 0 || i >= d.len {
+		panic("deque index out of range")
+	}
+
+	d.buf.Slice()[d.index(i)] = v
+}
+
+// Clear removes every value from the deque
+func (d *Deque[T]) Clear() {
+	d.head = 0
+	d.len = 0
+}
+
+// Iter returns an iterator over the values from front to back
+func (d *Deque[T]) Iter() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for i := range d.len {
+			if !yield(d.At(i)) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package alloc
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeque(t *testing.T) {
+	arena := NewExpandingAllocator(8)
+	d := Must(NewDeque[int](&arena, 0))
+
+	for x := range 10 {
+		assert.NoError(t, d.PushBack(x))
+		assert.NoError(t, d.PushFront(-x-1))
+	}
+
+	assert.Equal(t, 20, d.Len())
+	assert.Equal(t, []int{-10, -9, -8, -7, -6, -5, -4, -3, -2, -1, 0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, slices.Collect(d.Iter()))
+	assert.Equal(t, -10, d.At(0))
+	assert.Equal(t, 9, d.At(19))
+	assert.Panics(t, func() { d.At(20) })
+
+	d.Set(0, 100)
+	v, ok := d.PopFront()
+	assert.Equal(t, 100, v)
+	assert.True(t, ok)
+
+	v, ok = d.PopBack()
+	assert.Equal(t, 9, v)
+	assert.True(t, ok)
+
+	v, _ = d.Front()
+	assert.Equal(t, -9, v)
+	v, _ = d.Back()
+	assert.Equal(t, 8, v)
+
+	d.Clear()
+	_, ok = d.PopFront()
+	assert.False(t, ok)
+	_, ok = d.Back()
+	assert.False(t, ok)
+}
+
+func TestBoundedDeque(t *testing.T) {
+	arena := NewExpandingAllocator(8)
+
+	fixed := Must(NewBoundedDeque[int](&arena, 3, false))
+	for x := range 3 {
+		assert.NoError(t, fixed.PushBack(x))
+	}
+	assert.ErrorIs(t, fixed.PushBack(3), ErrFull)
+	assert.ErrorIs(t, fixed.PushFront(3), ErrFull)
+	assert.Equal(t, 3, fixed.Cap())
+
+	// overwriting keeps the most recent values
+	ring := Must(NewBoundedDeque[int](&arena, 3, true))
+	for x := range 5 {
+		assert.NoError(t, ring.PushBack(x))
+	}
+	assert.Equal(t, []int{2, 3, 4}, slices.Collect(ring.Iter()))
+
+	// pushing to the front drops the back
+	assert.NoError(t, ring.PushFront(1))
+	assert.Equal(t, []int{1, 2, 3}, slices.Collect(ring.Iter()))
+	assert.Equal(t, 3, ring.Cap())
+}
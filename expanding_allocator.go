@@ -15,7 +15,7 @@ type ExpandingAllocator struct {
 }
 
 // ensure we implement allocator
-var _ Allocator = &ExpandingAllocator{}
+var _ ResetAllocator = &ExpandingAllocator{}
 
 // NewExpandingAllocator will create a new Expanding allocator
 func NewExpandingAllocator(size int) ExpandingAllocator {
@@ -29,13 +29,24 @@ func NewExpandingAllocator(size int) ExpandingAllocator {
 
 // Alloc reserves a section of memory and returns the offset to it. If we are going
 // to exhaust the memory, we create a new location for the memory with twice the size,
-// copy the data over and then allocate
+// copy the data over and then allocate. An *AllocError is only returned
+// when the size is too large for a go slice
 func (a *ExpandingAllocator) Alloc(size uintptr, alignment uintptr) (uintptr, error) {
 	// find the start by aligning
 	start := align(uintptr(len(*a.b)), alignment)
 	// find the end
 	end := start + size
 
+	// the doubled slice has to fit in an int, otherwise make panics
+	if end < start || end > math.MaxInt/2 {
+		return 0, &AllocError{
+			Size:      size,
+			Alignment: alignment,
+			Available: math.MaxInt/2 - uintptr(len(*a.b)),
+			Allocator: "ExpandingAllocator",
+		}
+	}
+
 	// if we are not large enough to hold the data we need to grow the underlying
 	// bytes and move our data over
 	if uintptr(cap(*a.b)) < end {
@@ -61,6 +72,20 @@ func (a *ExpandingAllocator) Offset(offset uintptr) unsafe.Pointer {
 	return unsafe.Pointer(&(*a.b)[offset])
 }
 
+// OffsetChecked returns the pointer to the offset supplied, or
+// ErrOutOfRange if size bytes at offset haven't all been allocated
+func (a *ExpandingAllocator) OffsetChecked(offset, size uintptr) (unsafe.Pointer, error) {
+	if err := checkRange(offset, size, uintptr(len(*a.b))); err != nil {
+		return nil, err
+	}
+
+	if size == 0 {
+		return unsafe.Pointer(&zeroSized), nil
+	}
+
+	return a.Offset(offset), nil
+}
+
 // Available always return MaxUInt64 since we will "never" run out of
 // memory
 func (a *ExpandingAllocator) Available() uintptr {
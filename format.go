@@ -0,0 +1,156 @@
+package alloc
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+// maxIntLen is the longest an integer can be when formatted, which is a
+// 64 bit integer in base 2 with a sign
+const maxIntLen = 65
+
+// appended finishes a write made by an append style function on
+// b.all()[:b.len]. If the result did not fit in the buffer append will have
+// moved it to the heap, in which case the new bytes are written over
+func (b *Buffer) appended(out []byte) error {
+	if len(out) <= b.cap {
+		b.len = len(out)
+		return nil
+	}
+
+	_, err := b.Write(out[b.len:])
+	return err
+}
+
+// Printf formats according to the format specifier, and writes the result
+// to the buffer. This behaves like fmt.Printf. The output is written
+// straight into the buffer, however long it is
+func (b *Buffer) Printf(format string, args ...any) error {
+	_, err := fmt.Fprintf(b, format, args...)
+	return err
+}
+
+// WriteInt writes the integer i in the given base
+func (b *Buffer) WriteInt(i int64, base int) error {
+	if err := b.Grow(maxIntLen); err != nil {
+		return err
+	}
+
+	return b.appended(strconv.AppendInt(b.all()[:b.len], i, base))
+}
+
+// WriteUint writes the unsigned integer u in the given base
+func (b *Buffer) WriteUint(u uint64, base int) error {
+	if err := b.Grow(maxIntLen); err != nil {
+		return err
+	}
+
+	return b.appended(strconv.AppendUint(b.all()[:b.len], u, base))
+}
+
+// WriteFloat writes the float f, see strconv.FormatFloat for the meaning
+// of the arguments
+func (b *Buffer) WriteFloat(f float64, format byte, prec, bitSize int) error {
+	if err := b.Grow(32); err != nil {
+		return err
+	}
+
+	return b.appended(strconv.AppendFloat(b.all()[:b.len], f, format, prec, bitSize))
+}
+
+// WriteBool writes "true" or "false"
+func (b *Buffer) WriteBool(v bool) error {
+	_, err := b.WriteString(strconv.FormatBool(v))
+	return err
+}
+
+// WriteQuote writes s as a double quoted go string literal
+func (b *Buffer) WriteQuote(s string) error {
+	if err := b.Grow(len(s) + 2); err != nil {
+		return err
+	}
+
+	return b.appended(strconv.AppendQuote(b.all()[:b.len], s))
+}
+
+// sprintfBuffers holds the Buffer headers used by Sprintf. Printf hands the
+// buffer to fmt as an io.Writer, which would move a local buffer to the heap
+var sprintfBuffers = sync.Pool{New: func() any { return new(Buffer) }}
+
+// Sprintf formats according to the format specifier and stores the result
+// as a String in the allocator. This behaves like fmt.Sprintf
+func Sprintf(a Allocator, format string, args ...any) (Ptr[String], error) {
+	b := sprintfBuffers.Get().(*Buffer)
+	defer func() {
+		// don't keep the allocator alive from the pool
+		*b = Buffer{}
+		sprintfBuffers.Put(b)
+	}()
+
+	var err error
+	if *b, err = NewBuffer(a, len(format)+16); err != nil {
+		return Ptr[String]{}, err
+	}
+
+	if err := b.Printf(format, args...); err != nil {
+		return Ptr[String]{}, err
+	}
+
+	return b.NewString()
+}
+
+// FormatInt stores the integer i in the given base as a String in the
+// allocator. This behaves like strconv.FormatInt
+func FormatInt(a Allocator, i int64, base int) (Ptr[String], error) {
+	var scratch [maxIntLen]byte
+	return NewStringFromBytes(a, strconv.AppendInt(scratch[:0], i, base))
+}
+
+// FormatUint stores the unsigned integer u in the given base as a String in
+// the allocator. This behaves like strconv.FormatUint
+func FormatUint(a Allocator, u uint64, base int) (Ptr[String], error) {
+	var scratch [maxIntLen]byte
+	return NewStringFromBytes(a, strconv.AppendUint(scratch[:0], u, base))
+}
+
+// Itoa stores the integer i as a String in the allocator. This behaves like
+// strconv.Itoa
+func Itoa(a Allocator, i int) (Ptr[String], error) {
+	return FormatInt(a, int64(i), 10)
+}
+
+// FormatFloat stores the float f as a String in the allocator. This behaves
+// like strconv.FormatFloat
+func FormatFloat(a Allocator, f float64, format byte, prec, bitSize int) (Ptr[String], error) {
+	b, err := NewBuffer(a, 24)
+	if err != nil {
+		return Ptr[String]{}, err
+	}
+
+	if err := b.WriteFloat(f, format, prec, bitSize); err != nil {
+		return Ptr[String]{}, err
+	}
+
+	return b.NewString()
+}
+
+// FormatBool stores "true" or "false" as a String in the allocator
+func FormatBool(a Allocator, v bool) (Ptr[String], error) {
+	return NewString(a, strconv.FormatBool(v))
+}
+
+// Quote stores s as a double quoted go string literal in the allocator.
+// This behaves like strconv.Quote
+func Quote(a Allocator, s string) (Ptr[String], error) {
+	b, err := NewBuffer(a, len(s)+2)
+	if err != nil {
+		return Ptr[String]{}, err
+	}
+
+	if err := b.WriteQuote(s); err != nil {
+		return Ptr[String]{}, err
+	}
+
+	return b.NewString()
+}
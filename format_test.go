@@ -0,0 +1,60 @@
+package alloc
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormat(t *testing.T) {
+	arena := NewExpandingAllocator(8)
+
+	assert.Equal(t, "user-42: 3.50 true", Must(Sprintf(&arena, "%s-%d: %.2f %t", "user", 42, 3.5, true)).Deref().Cast())
+	assert.Equal(t, strings.Repeat("x", 200), Must(Sprintf(&arena, "%s", strings.Repeat("x", 200))).Deref().Cast())
+	assert.Equal(t, "-9223372036854775808", Must(FormatInt(&arena, math.MinInt64, 10)).Deref().Cast())
+	assert.Equal(t, "ff", Must(FormatUint(&arena, 255, 16)).Deref().Cast())
+	assert.Equal(t, "123", Must(Itoa(&arena, 123)).Deref().Cast())
+	assert.Equal(t, "1e+308", Must(FormatFloat(&arena, 1e308, 'g', -1, 64)).Deref().Cast())
+	assert.Equal(t, "false", Must(FormatBool(&arena, false)).Deref().Cast())
+	assert.Equal(t, `"a\"b\n"`, Must(Quote(&arena, "a\"b\n")).Deref().Cast())
+
+	// floats which don't fit in the space we reserve are still written
+	f := Must(FormatFloat(&arena, 1e308, 'f', -1, 64)).Deref().Cast()
+	assert.Equal(t, 309, len(f))
+}
+
+func TestBufferFormat(t *testing.T) {
+	arena := NewExpandingAllocator(8)
+	b := Must(NewBuffer(&arena, 0))
+
+	b.WriteString("key=")
+	b.WriteInt(-7, 10)
+	b.WriteByte(' ')
+	b.WriteUint(7, 2)
+	b.WriteByte(' ')
+	b.WriteFloat(0.25, 'f', 2, 64)
+	b.WriteByte(' ')
+	b.WriteBool(true)
+	b.WriteByte(' ')
+	b.WriteQuote("hi")
+	b.Printf(" %03d", 5)
+
+	assert.Equal(t, `key=-7 111 0.25 true "hi" 005`, b.String())
+}
+
+func TestFormatAllocs(t *testing.T) {
+	arena := NewExpandingAllocator(pageSize)
+	// output much longer than the format has to grow the buffer
+	var long any = strings.Repeat("x", 200)
+	allocs := testing.AllocsPerRun(100, func() {
+		arena.Reset()
+		_, _ = Itoa(&arena, 123456)
+		_, _ = FormatFloat(&arena, 3.25, 'f', -1, 64)
+		_, _ = Quote(&arena, "hello")
+		_, _ = Sprintf(&arena, "%s", long)
+	})
+
+	assert.Equal(t, float64(0), allocs)
+}
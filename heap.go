@@ -0,0 +1,196 @@
+package alloc
+
+import (
+	"cmp"
+	"iter"
+)
+
+// Heap is a binary min heap whose values are stored in the allocator. The
+// order is decided by less, so the value returned by Pop is the one which
+// is less than every other value. Use a less function which reverses the
+// comparison for a max heap.
+//
+// The Heap holds the less function, so it must stay on the go heap or
+// stack where the garbage collector can see it. Only the values are stored
+// in the allocator.
+type Heap[T any] struct {
+	data Array[T]
+	len  int
+	less func(a, b T) bool
+}
+
+// NewHeap creates an empty heap in the allocator with room for size values
+// before it has to grow
+func NewHeap[T any](a Allocator, size int, less func(a, b T) bool) (Heap[T], error) {
+	data, err := NewArray[T](a, size)
+	if err != nil {
+		return Heap[T]{}, err
+	}
+
+	return Heap[T]{data: *data.Deref(), less: less}, nil
+}
+
+// NewOrderedHeap creates an empty heap for ordered types, where the
+// smallest value is popped first
+func NewOrderedHeap[T cmp.Ordered](a Allocator, size int) (Heap[T], error) {
+	return NewHeap(a, size, cmp.Less[T])
+}
+
+// HeapFrom turns the array into a heap in place, reordering the values.
+// The heap takes over the array, so the array should no longer be used
+// directly. When the heap grows it will allocate from the array's allocator,
+// so arr must have been created by NewArray, or be a view of such an array.
+// A zero Array has no allocator and the first Push to grow it will panic
+func HeapFrom[T any](arr Array[T], less func(a, b T) bool) Heap[T] {
+	h := Heap[T]{data: arr, len: arr.Length(), less: less}
+	for i := h.len/2 - 1; i >= 0; i-- {
+		h.down(i)
+	}
+
+	return h
+}
+
+// Len returns the number of values in the heap
+func (h *Heap[T]) Len() int {
+	return h.len
+}
+
+// Push adds v to the heap. Allocation errors are returned if the heap has
+// to grow
+func (h *Heap[T]) Push(v T) error {
+	if h.len == h.data.Length() {
+		size := h.data.Length() * 2
+		if size == 0 {
+			size = 8
+		}
+
+		data, err := h.data.Expand(size)
+		if err != nil {
+			return err
+		}
+
+		h.data = data
+	}
+
+	h.data.Slice()[h.len] = v
+	h.len++
+	h.up(h.len - 1)
+	return nil
+}
+
+// Pop removes and returns the smallest value, false is returned if the heap
+// is empty
+func (h *Heap[T]) Pop() (T, bool) {
+	if h.len == 0 {
+		return *new(T), false
+	}
+
+	return h.Remove(0), true
+}
+
+// Peek returns the smallest value without removing it, false is returned
+// if the heap is empty
+func (h *Heap[T]) Peek() (T, bool) {
+	if h.len == 0 {
+		return *new(T), false
+	}
+
+	return h.data.Slice()[0], true
+}
+
+// At returns the value at index i, the order of the values is the order
+// they are stored in the heap
+func (h *Heap[T]) At(i int) T {
+	if i < 0 || i >= h.len {
+		panic("heap index out of range")
+	}
+
+	return h.data.Slice()[i]
+}
+
+// Set replaces the value at index i and restores the heap order
+func (h *Heap[T]) Set(i int, v T) {
+	if i < 0 || i >= h.len {
+		panic("heap index out of range")
+	}
+
+	h.data.Slice()[i] = v
+	h.Fix(i)
+}
+
+// Fix restores the heap order after the value at index i has changed. This
+// is cheaper than removing the value and pushing it again
+func (h *Heap[T]) Fix(i int) {
+	if !h.down(i) {
+		h.up(i)
+	}
+}
+
+// Remove removes and returns the value at index i
+func (h *Heap[T]) Remove(i int) T {
+	if i < 0 || i >= h.len {
+		panic("heap index out of range")
+	}
+
+	vals := h.data.Slice()
+	v := vals[i]
+	h.len--
+	if i != h.len {
+		vals[i] = vals[h.len]
+		h.Fix(i)
+	}
+
+	return v
+}
+
+// Iter returns an iterator of the values in the order they are stored,
+// which is not sorted
+func (h *Heap[T]) Iter() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for i := range h.len {
+			if !yield(h.data.Slice()[i]) {
+				return
+			}
+		}
+	}
+}
+
+// up moves the value at i towards the root until it is in order
+func (h *Heap[T]) up(i int) {
+	vals := h.data.Slice()
+	for i > 0 {
+		parent := (i - 1) / 2
+		if !h.less(vals[i], vals[parent]) {
+			return
+		}
+
+		vals[i], vals[parent] = vals[parent], vals[i]
+		i = parent
+	}
+}
+
+// down moves the value at i away from the root until it is in order. It
+// returns true if the value moved
+func (h *Heap[T]) down(i int) bool {
+	vals := h.data.Slice()
+	start := i
+	for {
+		child := 2*i + 1
+		if child >= h.len {
+			break
+		}
+
+		if right := child + 1; right < h.len && h.less(vals[right], vals[child]) {
+			child = right
+		}
+
+		if !h.less(vals[child], vals[i]) {
+			break
+		}
+
+		vals[i], vals[child] = vals[child], vals[i]
+		i = child
+	}
+
+	return i > start
+}
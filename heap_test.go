@@ -0,0 +1,81 @@
+package alloc
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeap(t *testing.T) {
+	arena := NewExpandingAllocator(8)
+	h := Must(NewOrderedHeap[int](&arena, 0))
+
+	rng := rand.New(rand.NewPCG(1, 2))
+	var want []int
+	for range 100 {
+		v := rng.IntN(1000)
+		want = append(want, v)
+		assert.NoError(t, h.Push(v))
+	}
+	slices.Sort(want)
+
+	v, ok := h.Peek()
+	assert.Equal(t, want[0], v)
+	assert.True(t, ok)
+	assert.Equal(t, 100, h.Len())
+
+	var got []int
+	for h.Len() > 0 {
+		v, _ := h.Pop()
+		got = append(got, v)
+	}
+	assert.Equal(t, want, got)
+
+	_, ok = h.Pop()
+	assert.False(t, ok)
+}
+
+func TestHeapFix(t *testing.T) {
+	type task struct {
+		name     string
+		priority int
+	}
+
+	arena := NewExpandingAllocator(8)
+	h := Must(NewHeap(&arena, 4, func(a, b task) bool { return a.priority > b.priority }))
+	h.Push(task{"a", 1})
+	h.Push(task{"b", 5})
+	h.Push(task{"c", 3})
+
+	// bump the priority of a above everything else
+	for i := range h.Len() {
+		if h.At(i).name == "a" {
+			h.Set(i, task{"a", 10})
+		}
+	}
+
+	var names []string
+	for h.Len() > 0 {
+		v, _ := h.Pop()
+		names = append(names, v.name)
+	}
+	assert.Equal(t, []string{"a", "b", "c"}, names)
+}
+
+func TestHeapFrom(t *testing.T) {
+	arena := NewExpandingAllocator(8)
+	arr := Must(NewArray[int](&arena, 6))
+	copy(arr.Deref().Slice(), []int{5, 2, 8, 1, 9, 3})
+
+	h := HeapFrom(*arr.Deref(), func(a, b int) bool { return a < b })
+	h.Push(0)
+
+	var got []int
+	for h.Len() > 0 {
+		v, _ := h.Pop()
+		got = append(got, v)
+	}
+	assert.Equal(t, []int{0, 1, 2, 3, 5, 8, 9}, got)
+}
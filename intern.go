@@ -0,0 +1,125 @@
+package alloc
+
+import (
+	"hash/maphash"
+	"unsafe"
+)
+
+// Interner deduplicates strings stored in the allocator. Interning the same
+// bytes twice returns the same String, so repeated values like object keys
+// only have their bytes stored once. Lookups are done with an open
+// addressed hash table which is also stored in the allocator.
+type Interner struct {
+	table hashTable[Ptr[String]]
+	stats InternStats
+}
+
+// InternStats reports how well the interner is deduplicating strings
+type InternStats struct {
+	// Len is the number of unique strings
+	Len int
+	// Hits is the number of times a string was already interned
+	Hits int
+	// Misses is the number of times a new string was stored
+	Misses int
+}
+
+// HitRatio returns the fraction of lookups which found an existing string
+func (s InternStats) HitRatio() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// NewInterner creates an interner in the allocator with room for size
+// strings before it has to grow
+func NewInterner(a Allocator, size int) (Interner, error) {
+	table, err := newHashTable[Ptr[String]](a, size)
+	if err != nil {
+		return Interner{}, err
+	}
+
+	return Interner{table: table}, nil
+}
+
+// Intern returns the String holding s, storing it in the allocator if it
+// hasn't been seen before
+func (i *Interner) Intern(s string) (Ptr[String], error) {
+	hash := maphash.String(i.table.seed, s)
+	x, found, err := i.lookup(hash, s)
+	if found || err != nil {
+		return i.slot(x, err)
+	}
+
+	str, err := NewString(i.table.slots.data.alloc, s)
+	if err != nil {
+		return Ptr[String]{}, err
+	}
+
+	i.insert(x, hash, str)
+	return str, nil
+}
+
+// InternBytes is like Intern, but takes a byte slice. b is only copied
+// when it hasn't been seen before
+func (i *Interner) InternBytes(b []byte) (Ptr[String], error) {
+	hash := maphash.Bytes(i.table.seed, b)
+	// the string is only compared against, it doesn't outlive the call
+	x, found, err := i.lookup(hash, unsafe.String(unsafe.SliceData(b), len(b)))
+	if found || err != nil {
+		return i.slot(x, err)
+	}
+
+	str, err := NewStringFromBytes(i.table.slots.data.alloc, b)
+	if err != nil {
+		return Ptr[String]{}, err
+	}
+
+	i.insert(x, hash, str)
+	return str, nil
+}
+
+// Len returns the number of unique strings in the interner
+func (i *Interner) Len() int {
+	return i.table.len
+}
+
+// Stats returns counters describing how the interner has been used
+func (i *Interner) Stats() InternStats {
+	stats := i.stats
+	stats.Len = i.table.len
+	return stats
+}
+
+// lookup finds the slot holding s, counting a hit when it is found. When
+// it isn't, room is made for one more string and the empty slot where s
+// belongs is returned
+func (i *Interner) lookup(hash uint64, s string) (int, bool, error) {
+	x, found := i.table.find(hash, func(str Ptr[String]) bool {
+		return str.Deref().Cast() == s
+	})
+	if found {
+		i.stats.Hits++
+		return x, true, nil
+	}
+
+	x, err := i.table.reserve(hash)
+	return x, false, err
+}
+
+// slot returns the String in slot x, or the error from looking it up
+func (i *Interner) slot(x int, err error) (Ptr[String], error) {
+	if err != nil {
+		return Ptr[String]{}, err
+	}
+
+	return *i.table.at(x), nil
+}
+
+// insert stores str in the empty slot x
+func (i *Interner) insert(x int, hash uint64, str Ptr[String]) {
+	i.table.put(x, hash, str)
+	i.stats.Misses++
+}
@@ -0,0 +1,78 @@
+package alloc
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterner(t *testing.T) {
+	arena := NewExpandingAllocator(8)
+	in := Must(NewInterner(&arena, 0))
+
+	a := Must(in.Intern("key"))
+	b := Must(in.InternBytes([]byte("key")))
+	c := Must(in.Intern("other"))
+
+	// the same string shares the same bytes
+	assert.Equal(t, a.offset, b.offset)
+	assert.Equal(t, a.Deref().data.offset, b.Deref().data.offset)
+	assert.NotEqual(t, a.offset, c.offset)
+	assert.Equal(t, "key", a.Deref().Cast())
+	assert.Equal(t, "other", c.Deref().Cast())
+
+	// grow the table well past its initial size
+	for x := range 1000 {
+		Must(in.Intern(strconv.Itoa(x)))
+	}
+
+	for x := range 1000 {
+		s := Must(in.Intern(strconv.Itoa(x)))
+		assert.Equal(t, strconv.Itoa(x), s.Deref().Cast())
+	}
+
+	assert.Equal(t, a.offset, Must(in.Intern("key")).offset)
+	assert.Equal(t, InternStats{Len: 1002, Hits: 1002, Misses: 1002}, in.Stats())
+	assert.Equal(t, 0.5, in.Stats().HitRatio())
+}
+
+func TestInternerAllocs(t *testing.T) {
+	arena := NewExpandingAllocator(pageSize)
+	in := Must(NewInterner(&arena, 0))
+	Must(in.Intern("key"))
+	key := []byte("key")
+	long := []byte("a key which is longer than thirty two bytes")
+	Must(in.InternBytes(long))
+
+	allocs := testing.AllocsPerRun(100, func() {
+		_, _ = in.Intern("key")
+		_, _ = in.InternBytes(key)
+		_, _ = in.InternBytes(long)
+	})
+
+	assert.Equal(t, float64(0), allocs)
+}
+
+func TestInternerExhausted(t *testing.T) {
+	arena := NewPageAllocator()
+	in := Must(NewInterner(&arena, 0))
+
+	var err error
+	for x := 0; err == nil; x++ {
+		var s Ptr[String]
+		s, err = in.Intern(strconv.Itoa(x))
+		if err != nil {
+			assert.True(t, s.IsNull())
+		}
+	}
+
+	assert.ErrorIs(t, err, ErrMemoryExhausted)
+
+	// only the strings which were stored are counted
+	stats := in.Stats()
+	assert.Equal(t, stats.Len, stats.Misses)
+	for x := range stats.Len {
+		assert.Equal(t, strconv.Itoa(x), Must(in.Intern(strconv.Itoa(x))).Deref().Cast())
+	}
+}
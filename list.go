@@ -0,0 +1,220 @@
+package alloc
+
+import "iter"
+
+// ListNode is a single node within a List. The nodes live in the allocator
+// and link to each other with Ptrs, which hold an offset rather than an
+// address so the links survive the allocator moving its memory. Each Ptr
+// also holds the Allocator it came from, which the garbage collector can't
+// see within the allocator's memory, so keep the allocator alive while the
+// list is in use
+type ListNode[T any] struct {
+	next  Ptr[ListNode[T]]
+	prev  Ptr[ListNode[T]]
+	Value T
+}
+
+// Next returns the next node in the list, or a null Ptr at the back
+func (n *ListNode[T]) Next() Ptr[ListNode[T]] {
+	return n.next
+}
+
+// Prev returns the previous node in the list, or a null Ptr at the front
+func (n *ListNode[T]) Prev() Ptr[ListNode[T]] {
+	return n.prev
+}
+
+// List is a doubly linked list whose nodes are stored in the allocator.
+// Nodes are referenced with a Ptr[ListNode[T]], which stays valid as long
+// as the node is in the list. Passing a node from another list is not
+// checked, and will corrupt both lists.
+//
+// The List is a header holding the front and back of the list.
+type List[T any] struct {
+	front Ptr[ListNode[T]]
+	back  Ptr[ListNode[T]]
+	len   int
+	alloc Allocator
+}
+
+// NewList creates an empty list which stores its nodes in the allocator
+func NewList[T any](a Allocator) List[T] {
+	return List[T]{alloc: a}
+}
+
+// Len returns the number of nodes in the list
+func (l *List[T]) Len() int {
+	return l.len
+}
+
+// Front returns the first node in the list, or a null Ptr if the list is
+// empty
+func (l *List[T]) Front() Ptr[ListNode[T]] {
+	return l.front
+}
+
+// Back returns the last node in the list, or a null Ptr if the list is
+// empty
+func (l *List[T]) Back() Ptr[ListNode[T]] {
+	return l.back
+}
+
+// newNode creates an unlinked node holding v
+func (l *List[T]) newNode(v T) (Ptr[ListNode[T]], error) {
+	n, err := New[ListNode[T]](l.alloc)
+	if err != nil {
+		return Ptr[ListNode[T]]{}, err
+	}
+
+	n.Set(ListNode[T]{Value: v})
+	return n, nil
+}
+
+// link inserts the unlinked node n after prev. If prev is null n becomes
+// the front of the list
+func (l *List[T]) link(n, prev Ptr[ListNode[T]]) {
+	next := l.front
+	if !prev.IsNull() {
+		next = prev.Deref().next
+	}
+
+	node := n.Deref()
+	node.prev = prev
+	node.next = next
+
+	if prev.IsNull() {
+		l.front = n
+	} else {
+		prev.Deref().next = n
+	}
+
+	if next.IsNull() {
+		l.back = n
+	} else {
+		next.Deref().prev = n
+	}
+
+	l.len++
+}
+
+// unlink takes n out of the list, leaving the node in the allocator
+func (l *List[T]) unlink(n Ptr[ListNode[T]]) {
+	node := n.Deref()
+	if node.prev.IsNull() {
+		l.front = node.next
+	} else {
+		node.prev.Deref().next = node.next
+	}
+
+	if node.next.IsNull() {
+		l.back = node.prev
+	} else {
+		node.next.Deref().prev = node.prev
+	}
+
+	node.next.Null()
+	node.prev.Null()
+	l.len--
+}
+
+// insert creates a node holding v and links it after prev
+func (l *List[T]) insert(v T, prev Ptr[ListNode[T]]) (Ptr[ListNode[T]], error) {
+	n, err := l.newNode(v)
+	if err != nil {
+		return Ptr[ListNode[T]]{}, err
+	}
+
+	l.link(n, prev)
+	return n, nil
+}
+
+// PushFront adds v to the front of the list and returns its node
+func (l *List[T]) PushFront(v T) (Ptr[ListNode[T]], error) {
+	return l.insert(v, Ptr[ListNode[T]]{})
+}
+
+// PushBack adds v to the back of the list and returns its node
+func (l *List[T]) PushBack(v T) (Ptr[ListNode[T]], error) {
+	return l.insert(v, l.back)
+}
+
+// InsertAfter adds v directly after mark and returns its node
+func (l *List[T]) InsertAfter(mark Ptr[ListNode[T]], v T) (Ptr[ListNode[T]], error) {
+	return l.insert(v, mark)
+}
+
+// InsertBefore adds v directly before mark and returns its node
+func (l *List[T]) InsertBefore(mark Ptr[ListNode[T]], v T) (Ptr[ListNode[T]], error) {
+	return l.insert(v, mark.Deref().prev)
+}
+
+// Remove takes the node out of the list and returns its value. The memory
+// for the node is not reused
+func (l *List[T]) Remove(n Ptr[ListNode[T]]) T {
+	l.unlink(n)
+	return n.Deref().Value
+}
+
+// MoveToFront moves the node to the front of the list
+func (l *List[T]) MoveToFront(n Ptr[ListNode[T]]) {
+	if l.front == n {
+		return
+	}
+
+	l.unlink(n)
+	l.link(n, Ptr[ListNode[T]]{})
+}
+
+// MoveToBack moves the node to the back of the list
+func (l *List[T]) MoveToBack(n Ptr[ListNode[T]]) {
+	if l.back == n {
+		return
+	}
+
+	l.unlink(n)
+	l.link(n, l.back)
+}
+
+// All returns an iterator over the values from front to back. The current
+// node can be removed while iterating
+func (l *List[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for n := range l.Nodes() {
+			if !yield(n.Deref().Value) {
+				return
+			}
+		}
+	}
+}
+
+// Backward returns an iterator over the values from back to front. The
+// current node can be removed while iterating
+func (l *List[T]) Backward() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for n := l.back; !n.IsNull(); {
+			node := n.Deref()
+			prev := node.prev
+			if !yield(node.Value) {
+				return
+			}
+
+			n = prev
+		}
+	}
+}
+
+// Nodes returns an iterator over the nodes from front to back. The current
+// node can be removed while iterating, but not moved, since moving it to
+// the back would visit it again
+func (l *List[T]) Nodes() iter.Seq[Ptr[ListNode[T]]] {
+	return func(yield func(Ptr[ListNode[T]]) bool) {
+		for n := l.front; !n.IsNull(); {
+			next := n.Deref().next
+			if !yield(n) {
+				return
+			}
+
+			n = next
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package alloc
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestList(t *testing.T) {
+	arena := NewExpandingAllocator(8)
+	l := NewList[int](&arena)
+
+	two := Must(l.PushBack(2))
+	Must(l.PushFront(1))
+	four := Must(l.PushBack(4))
+	Must(l.InsertAfter(two, 3))
+	Must(l.InsertBefore(four, 35))
+
+	assert.Equal(t, 5, l.Len())
+	assert.Equal(t, []int{1, 2, 3, 35, 4}, slices.Collect(l.All()))
+	assert.Equal(t, []int{4, 35, 3, 2, 1}, slices.Collect(l.Backward()))
+
+	assert.Equal(t, 35, l.Remove(four.Deref().Prev()))
+	assert.Equal(t, []int{1, 2, 3, 4}, slices.Collect(l.All()))
+
+	l.MoveToFront(four)
+	l.MoveToBack(two)
+	assert.Equal(t, []int{4, 1, 3, 2}, slices.Collect(l.All()))
+	assert.Equal(t, four, l.Front())
+	assert.Equal(t, two, l.Back())
+	assert.True(t, l.Front().Deref().Prev().IsNull())
+	assert.True(t, l.Back().Deref().Next().IsNull())
+
+	// nodes can be removed while iterating
+	for n := range l.Nodes() {
+		if n.Deref().Value%2 == 0 {
+			l.Remove(n)
+		}
+	}
+	assert.Equal(t, []int{1, 3}, slices.Collect(l.All()))
+
+	for n := range l.Nodes() {
+		l.Remove(n)
+	}
+	assert.Equal(t, 0, l.Len())
+	assert.True(t, l.Front().IsNull())
+	assert.True(t, l.Back().IsNull())
+}
@@ -0,0 +1,234 @@
+package alloc
+
+import (
+	"hash/maphash"
+	"iter"
+	"unsafe"
+)
+
+// lruEntry is the value stored in each node of the recency list
+type lruEntry[K, V any] struct {
+	key  K
+	val  V
+	hash uint64
+	size uintptr
+}
+
+// LRUOptions controls the capacity of an LRU and what happens to the
+// entries it evicts
+type LRUOptions[K, V any] struct {
+	// MaxEntries is the most entries the cache holds, 0 is unlimited
+	MaxEntries int
+
+	// MaxBytes is the most bytes the cache holds according to Size, 0 is
+	// unlimited
+	MaxBytes uintptr
+
+	// Size returns the number of bytes an entry uses. If it isn't set
+	// the size of the key and value types is used
+	Size func(key K, val V) uintptr
+
+	// OnEvict is called with each entry removed to make room
+	OnEvict func(key K, val V)
+}
+
+// LRU is a cache which evicts the least recently used entries once it is
+// over capacity. The entries and the index used to find them are stored in
+// the allocator. Like Object, C is the go primitive type of the key and K
+// is the type stored in the allocator.
+//
+// Evicted entries are kept on a free list and their memory is reused by the
+// next entry added, so a full cache stops allocating. Keys and values which
+// point to other memory in the allocator, like String, are not freed.
+//
+// The LRU holds the functions in LRUOptions, so it must stay on the go heap
+// or stack where the garbage collector can see them.
+type LRU[C comparable, K Primitive[C], V any] struct {
+	// index finds the node in the recency list holding each key
+	index hashTable[Ptr[ListNode[lruEntry[K, V]]]]
+	list  List[lruEntry[K, V]]
+	free  List[lruEntry[K, V]]
+	bytes uintptr
+	opts  LRUOptions[K, V]
+}
+
+// NewLRU creates an empty cache in the allocator
+func NewLRU[C comparable, K Primitive[C], V any](a Allocator, opts LRUOptions[K, V]) (LRU[C, K, V], error) {
+	if opts.Size == nil {
+		opts.Size = func(K, V) uintptr {
+			return unsafe.Sizeof(lruEntry[K, V]{})
+		}
+	}
+
+	index, err := newHashTable[Ptr[ListNode[lruEntry[K, V]]]](a, opts.MaxEntries)
+	if err != nil {
+		return LRU[C, K, V]{}, err
+	}
+
+	return LRU[C, K, V]{
+		index: index,
+		list:  NewList[lruEntry[K, V]](a),
+		free:  NewList[lruEntry[K, V]](a),
+		opts:  opts,
+	}, nil
+}
+
+// Len returns the number of entries in the cache
+func (c *LRU[C, K, V]) Len() int {
+	return c.list.Len()
+}
+
+// Bytes returns the size of every entry in the cache
+func (c *LRU[C, K, V]) Bytes() uintptr {
+	return c.bytes
+}
+
+// find returns the slot holding key and true. If the key isn't in the index
+// the empty slot where it belongs is returned with false
+func (c *LRU[C, K, V]) find(hash uint64, key C) (int, bool) {
+	return c.index.find(hash, func(node Ptr[ListNode[lruEntry[K, V]]]) bool {
+		return node.Deref().Value.key.Cast() == key
+	})
+}
+
+// Get returns the value stored for key and marks it as the most recently
+// used. The empty value of V and false are returned if it isn't cached
+func (c *LRU[C, K, V]) Get(key C) (V, bool) {
+	x, found := c.find(maphash.Comparable(c.index.seed, key), key)
+	if !found {
+		return *new(V), false
+	}
+
+	node := *c.index.at(x)
+	c.list.MoveToFront(node)
+	return node.Deref().Value.val, true
+}
+
+// Peek returns the value stored for key without changing how recently it
+// was used
+func (c *LRU[C, K, V]) Peek(key C) (V, bool) {
+	x, found := c.find(maphash.Comparable(c.index.seed, key), key)
+	if !found {
+		return *new(V), false
+	}
+
+	return c.index.at(x).Deref().Value.val, true
+}
+
+// Has returns if the key is cached, without changing how recently it was
+// used
+func (c *LRU[C, K, V]) Has(key C) bool {
+	_, found := c.find(maphash.Comparable(c.index.seed, key), key)
+	return found
+}
+
+// Set stores val under key as the most recently used entry, then evicts
+// the least recently used entries until the cache is within capacity.
+// Allocation errors are returned when the cache has to grow
+func (c *LRU[C, K, V]) Set(key K, val V) error {
+	hash := maphash.Comparable(c.index.seed, key.Cast())
+	size := c.opts.Size(key, val)
+
+	x, found := c.find(hash, key.Cast())
+	if found {
+		node := *c.index.at(x)
+		entry := &node.Deref().Value
+		c.bytes += size - entry.size
+		entry.key, entry.val, entry.size = key, val, size
+		c.list.MoveToFront(node)
+		c.evict()
+		return nil
+	}
+
+	// make room first when the cache is full, so the evicted node is reused
+	for c.opts.MaxEntries > 0 && c.list.Len() >= c.opts.MaxEntries {
+		c.evictBack()
+	}
+
+	x, err := c.index.reserve(hash)
+	if err != nil {
+		return err
+	}
+
+	// reuse an evicted node before allocating a new one
+	entry := lruEntry[K, V]{key: key, val: val, hash: hash, size: size}
+	node := c.free.Front()
+	if node.IsNull() {
+		if node, err = c.list.newNode(entry); err != nil {
+			return err
+		}
+	} else {
+		c.free.unlink(node)
+		node.Deref().Value = entry
+	}
+
+	c.index.put(x, hash, node)
+	c.list.link(node, Ptr[ListNode[lruEntry[K, V]]]{})
+	c.bytes += size
+	c.evict()
+	return nil
+}
+
+// Delete removes the key from the cache without calling OnEvict, returning
+// true if it was cached
+func (c *LRU[C, K, V]) Delete(key C) bool {
+	x, found := c.find(maphash.Comparable(c.index.seed, key), key)
+	if !found {
+		return false
+	}
+
+	c.remove(x)
+	return true
+}
+
+// remove takes the entry in slot x out of the cache, and puts its node on
+// the free list. The removed entry is returned
+func (c *LRU[C, K, V]) remove(x int) lruEntry[K, V] {
+	node := *c.index.at(x)
+	// the index shifts entries back rather than leaving deleted slots, so
+	// evicting entries never forces it to be rebuilt
+	c.index.delete(x)
+
+	entry := node.Deref().Value
+	c.list.unlink(node)
+	c.free.link(node, Ptr[ListNode[lruEntry[K, V]]]{})
+	c.bytes -= entry.size
+	return entry
+}
+
+// over returns if the cache is holding more than its capacity
+func (c *LRU[C, K, V]) over() bool {
+	return (c.opts.MaxEntries > 0 && c.list.Len() > c.opts.MaxEntries) ||
+		(c.opts.MaxBytes > 0 && c.bytes > c.opts.MaxBytes)
+}
+
+// evict removes the least recently used entries until the cache is within
+// capacity
+func (c *LRU[C, K, V]) evict() {
+	for c.over() {
+		c.evictBack()
+	}
+}
+
+// evictBack removes the least recently used entry, passing it to OnEvict
+func (c *LRU[C, K, V]) evictBack() {
+	back := c.list.Back().Deref().Value
+	x, _ := c.find(back.hash, back.key.Cast())
+	entry := c.remove(x)
+
+	if c.opts.OnEvict != nil {
+		c.opts.OnEvict(entry.key, entry.val)
+	}
+}
+
+// All returns an iterator over the entries from the most to the least
+// recently used. Iterating does not change how recently entries were used
+func (c *LRU[C, K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for entry := range c.list.All() {
+			if !yield(entry.key, entry.val) {
+				return
+			}
+		}
+	}
+}
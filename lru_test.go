@@ -0,0 +1,144 @@
+package alloc
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// lruKeys collects the keys from most to least recently used
+func lruKeys[C comparable, K Primitive[C], V any](c *LRU[C, K, V]) []C {
+	var keys []C
+	for k := range c.All() {
+		keys = append(keys, k.Cast())
+	}
+
+	return keys
+}
+
+func TestLRU(t *testing.T) {
+	arena := NewExpandingAllocator(8)
+
+	var evicted []int
+	cache := Must(NewLRU[int, Int, string](&arena, LRUOptions[Int, string]{
+		MaxEntries: 3,
+		OnEvict: func(k Int, _ string) {
+			evicted = append(evicted, int(k))
+		},
+	}))
+
+	for x := range 3 {
+		assert.NoError(t, cache.Set(Int(x), "v"))
+	}
+	assert.Equal(t, []int{2, 1, 0}, lruKeys(&cache))
+
+	// getting a key makes it the most recently used
+	v, ok := cache.Get(0)
+	assert.True(t, ok)
+	assert.Equal(t, "v", v)
+	assert.Equal(t, []int{0, 2, 1}, lruKeys(&cache))
+
+	// peeking doesn't
+	_, ok = cache.Peek(1)
+	assert.True(t, ok)
+	assert.Equal(t, []int{0, 2, 1}, lruKeys(&cache))
+
+	assert.NoError(t, cache.Set(3, "w"))
+	assert.Equal(t, []int{1}, evicted)
+	assert.Equal(t, []int{3, 0, 2}, lruKeys(&cache))
+	assert.False(t, cache.Has(1))
+
+	// updating a key doesn't evict anything
+	assert.NoError(t, cache.Set(2, "x"))
+	v, _ = cache.Peek(2)
+	assert.Equal(t, "x", v)
+	assert.Equal(t, 3, cache.Len())
+	assert.Equal(t, []int{1}, evicted)
+
+	assert.True(t, cache.Delete(0))
+	assert.False(t, cache.Delete(0))
+	assert.Equal(t, []int{2, 3}, lruKeys(&cache))
+	assert.Equal(t, []int{1}, evicted)
+
+	_, ok = cache.Get(0)
+	assert.False(t, ok)
+}
+
+func TestLRUBytes(t *testing.T) {
+	arena := NewExpandingAllocator(8)
+	cache := Must(NewLRU[string, String, int](&arena, LRUOptions[String, int]{
+		MaxBytes: 10,
+		Size: func(k String, _ int) uintptr {
+			return uintptr(k.Len())
+		},
+	}))
+
+	for _, s := range []string{"abcd", "efg", "hi"} {
+		assert.NoError(t, cache.Set(*Must(NewString(&arena, s)).Deref(), len(s)))
+	}
+	assert.Equal(t, uintptr(9), cache.Bytes())
+
+	assert.NoError(t, cache.Set(*Must(NewString(&arena, "jklm")).Deref(), 4))
+	assert.Equal(t, []string{"jklm", "hi", "efg"}, lruKeys(&cache))
+	assert.Equal(t, uintptr(9), cache.Bytes())
+
+	// an entry larger than the cache evicts everything, including itself
+	assert.NoError(t, cache.Set(*Must(NewString(&arena, "nopqrstuvwxyz")).Deref(), 13))
+	assert.Equal(t, 0, cache.Len())
+	assert.Equal(t, uintptr(0), cache.Bytes())
+}
+
+func TestLRUReuse(t *testing.T) {
+	arena := NewExpandingAllocator(8)
+	cache := Must(NewLRU[int, Int, int](&arena, LRUOptions[Int, int]{MaxEntries: 100}))
+
+	for x := range 100 {
+		assert.NoError(t, cache.Set(Int(x), x))
+	}
+
+	// once the cache is full, evicted nodes are reused instead of allocating
+	used := len(*arena.b)
+	for x := 100; x < 10000; x++ {
+		assert.NoError(t, cache.Set(Int(x), x))
+	}
+
+	assert.Equal(t, used, len(*arena.b))
+	assert.Equal(t, 100, cache.Len())
+	for x := 9900; x < 10000; x++ {
+		v, ok := cache.Peek(x)
+		assert.True(t, ok)
+		assert.Equal(t, x, v)
+	}
+}
+
+func TestLRURandom(t *testing.T) {
+	arena := NewExpandingAllocator(8)
+	cache := Must(NewLRU[int, Int, int](&arena, LRUOptions[Int, int]{}))
+
+	// deleting shifts entries around the index, check against a map
+	rng := rand.New(rand.NewPCG(1, 2))
+	expected := map[int]int{}
+	for range 5000 {
+		k := rng.IntN(500)
+		if rng.IntN(2) == 0 {
+			_, ok := expected[k]
+			assert.Equal(t, ok, cache.Delete(k))
+			delete(expected, k)
+			continue
+		}
+
+		assert.NoError(t, cache.Set(Int(k), k))
+		expected[k] = k
+	}
+
+	assert.Equal(t, len(expected), cache.Len())
+	for k := range 500 {
+		v, ok := cache.Peek(k)
+		_, want := expected[k]
+		assert.Equal(t, want, ok)
+		if ok {
+			assert.Equal(t, k, v)
+		}
+	}
+}
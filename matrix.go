@@ -0,0 +1,341 @@
+package alloc
+
+import (
+	"fmt"
+	"iter"
+)
+
+// Matrix is a two dimensional view over an Array. The position of each
+// element is found with a stride per dimension, so rows, columns, sub
+// matrices and the transpose are all views over the same elements and
+// nothing is copied. Changes made through a view are seen by every other
+// view of the Array.
+//
+// The Matrix is a header and can be passed around by value.
+type Matrix[T any] struct {
+	data       Array[T]
+	offset     int
+	rows, cols int
+	// rowStride and colStride are how far apart elements are when moving
+	// down a row or along a column
+	rowStride, colStride int
+}
+
+// NewMatrix creates a matrix in the allocator with every element set to
+// the empty value of T
+func NewMatrix[T any](a Allocator, rows, cols int) (Matrix[T], error) {
+	arr, err := NewArray[T](a, rows*cols)
+	if err != nil {
+		return Matrix[T]{}, err
+	}
+
+	clear(arr.Deref().Slice())
+	return MatrixFrom(*arr.Deref(), rows, cols), nil
+}
+
+// MatrixFrom views the array as a matrix in row major order. The array
+// must have exactly rows*cols elements
+func MatrixFrom[T any](arr Array[T], rows, cols int) Matrix[T] {
+	if rows < 0 || cols < 0 || rows*cols != arr.Length() {
+		panic(fmt.Sprintf("can not view %d elements as a %dx%d matrix", arr.Length(), rows, cols))
+	}
+
+	return Matrix[T]{data: arr, rows: rows, cols: cols, rowStride: cols, colStride: 1}
+}
+
+// Rows returns the number of rows in the matrix
+func (m Matrix[T]) Rows() int {
+	return m.rows
+}
+
+// Cols returns the number of columns in the matrix
+func (m Matrix[T]) Cols() int {
+	return m.cols
+}
+
+// ptr returns a Ptr to the element at row r and column c
+func (m Matrix[T]) ptr(r, c int) Ptr[T] {
+	if r < 0 || r >= m.rows || c < 0 || c >= m.cols {
+		panic(fmt.Sprintf("index [%d, %d] out of range for %dx%d matrix", r, c, m.rows, m.cols))
+	}
+
+	return m.data.ptr(m.offset + r*m.rowStride + c*m.colStride)
+}
+
+// At returns the element at row r and column c
+func (m Matrix[T]) At(r, c int) T {
+	return *m.ptr(r, c).Deref()
+}
+
+// Set replaces the element at row r and column c
+func (m Matrix[T]) Set(r, c int, val T) {
+	m.ptr(r, c).Set(val)
+}
+
+// Row returns a 1xN view of row r
+func (m Matrix[T]) Row(r int) Matrix[T] {
+	return m.View(r, 0, r+1, m.cols)
+}
+
+// Col returns an Nx1 view of column c
+func (m Matrix[T]) Col(c int) Matrix[T] {
+	return m.View(0, c, m.rows, c+1)
+}
+
+// View returns the rows from r0 up to r1 and the columns from c0 up to c1
+// as a matrix sharing the same elements
+func (m Matrix[T]) View(r0, c0, r1, c1 int) Matrix[T] {
+	if r0 < 0 || r1 < r0 || r1 > m.rows || c0 < 0 || c1 < c0 || c1 > m.cols {
+		panic(fmt.Sprintf("view [%d:%d, %d:%d] out of range for %dx%d matrix", r0, r1, c0, c1, m.rows, m.cols))
+	}
+
+	v := m
+	v.offset += r0*m.rowStride + c0*m.colStride
+	v.rows, v.cols = r1-r0, c1-c0
+	return v
+}
+
+// T returns the transpose of the matrix, rows become columns and columns
+// become rows
+func (m Matrix[T]) T() Matrix[T] {
+	m.rows, m.cols = m.cols, m.rows
+	m.rowStride, m.colStride = m.colStride, m.rowStride
+	return m
+}
+
+// All returns an iterator over the elements in row major order
+func (m Matrix[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for r := range m.rows {
+			for c := range m.cols {
+				if !yield(m.At(r, c)) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Cells returns an iterator over the row, column and element of every
+// element in row major order
+func (m Matrix[T]) Cells() iter.Seq2[[2]int, T] {
+	return func(yield func([2]int, T) bool) {
+		for r := range m.rows {
+			for c := range m.cols {
+				if !yield([2]int{r, c}, m.At(r, c)) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// tensorMaxDims is the most dimensions a Tensor can have. The shape is kept
+// in a fixed array so views never allocate
+const tensorMaxDims = 8
+
+// Tensor is an N dimensional view over an Array, up to tensorMaxDims
+// dimensions. Like Matrix, every view shares the same elements.
+//
+// The Tensor is a header and can be passed around by value.
+type Tensor[T any] struct {
+	data    Array[T]
+	offset  int
+	dims    int
+	shape   [tensorMaxDims]int
+	strides [tensorMaxDims]int
+}
+
+// NewTensor creates a tensor in the allocator with the shape, every element
+// is set to the empty value of T
+func NewTensor[T any](a Allocator, shape ...int) (Tensor[T], error) {
+	n := 1
+	for _, d := range shape {
+		n *= d
+	}
+
+	arr, err := NewArray[T](a, n)
+	if err != nil {
+		return Tensor[T]{}, err
+	}
+
+	clear(arr.Deref().Slice())
+	return TensorFrom(*arr.Deref(), shape...), nil
+}
+
+// TensorFrom views the array as a tensor with the shape in row major order.
+// The array must have exactly as many elements as the shape
+func TensorFrom[T any](arr Array[T], shape ...int) Tensor[T] {
+	if len(shape) > tensorMaxDims {
+		panic(fmt.Sprintf("tensor can not have more than %d dimensions", tensorMaxDims))
+	}
+
+	t := Tensor[T]{data: arr, dims: len(shape)}
+	n := 1
+	for x := len(shape) - 1; x >= 0; x-- {
+		if shape[x] < 0 {
+			panic(fmt.Sprintf("invalid tensor shape %v", shape))
+		}
+
+		t.shape[x], t.strides[x] = shape[x], n
+		n *= shape[x]
+	}
+
+	if n != arr.Length() {
+		panic(fmt.Sprintf("can not view %d elements with shape %v", arr.Length(), shape))
+	}
+
+	return t
+}
+
+// Dims returns the number of dimensions
+func (t Tensor[T]) Dims() int {
+	return t.dims
+}
+
+// Shape returns the size of each dimension
+func (t Tensor[T]) Shape() []int {
+	return append([]int(nil), t.shape[:t.dims]...)
+}
+
+// Len returns the number of elements in the tensor
+func (t Tensor[T]) Len() int {
+	n := 1
+	for _, d := range t.shape[:t.dims] {
+		n *= d
+	}
+
+	return n
+}
+
+// ptr returns a Ptr to the element at the index
+func (t Tensor[T]) ptr(idx []int) Ptr[T] {
+	if len(idx) != t.dims {
+		panic(fmt.Sprintf("index %v has %d dimensions, tensor has %d", idx, len(idx), t.dims))
+	}
+
+	i := t.offset
+	for x, v := range idx {
+		if v < 0 || v >= t.shape[x] {
+			panic(fmt.Sprintf("index %v out of range for shape %v", idx, t.shape[:t.dims]))
+		}
+
+		i += v * t.strides[x]
+	}
+
+	return t.data.ptr(i)
+}
+
+// At returns the element at the index, which needs a value for every
+// dimension
+func (t Tensor[T]) At(idx ...int) T {
+	return *t.ptr(idx).Deref()
+}
+
+// Set replaces the element at the index
+func (t Tensor[T]) Set(val T, idx ...int) {
+	t.ptr(idx).Set(val)
+}
+
+// Index returns a view of the tensor with the first dimension fixed at i,
+// so it has one less dimension. Indexing a matrix returns a row
+func (t Tensor[T]) Index(i int) Tensor[T] {
+	if t.dims == 0 || i < 0 || i >= t.shape[0] {
+		panic(fmt.Sprintf("index %d out of range for shape %v", i, t.shape[:t.dims]))
+	}
+
+	v := Tensor[T]{data: t.data, offset: t.offset + i*t.strides[0], dims: t.dims - 1}
+	copy(v.shape[:], t.shape[1:t.dims])
+	copy(v.strides[:], t.strides[1:t.dims])
+	return v
+}
+
+// Transpose returns a view with the dimensions reordered, dimension x of
+// the view is dimension perm[x] of the tensor. With no perm the dimensions
+// are reversed
+func (t Tensor[T]) Transpose(perm ...int) Tensor[T] {
+	v := t
+	if len(perm) == 0 {
+		for x := range t.dims {
+			v.shape[x], v.strides[x] = t.shape[t.dims-1-x], t.strides[t.dims-1-x]
+		}
+
+		return v
+	}
+
+	if len(perm) != t.dims {
+		panic(fmt.Sprintf("permutation %v does not match %d dimensions", perm, t.dims))
+	}
+
+	var seen [tensorMaxDims]bool
+	for x, p := range perm {
+		if p < 0 || p >= t.dims || seen[p] {
+			panic(fmt.Sprintf("invalid permutation %v", perm))
+		}
+
+		seen[p] = true
+		v.shape[x], v.strides[x] = t.shape[p], t.strides[p]
+	}
+
+	return v
+}
+
+// Matrix returns a two dimensional tensor as a Matrix view
+func (t Tensor[T]) Matrix() Matrix[T] {
+	if t.dims != 2 {
+		panic(fmt.Sprintf("tensor with %d dimensions is not a matrix", t.dims))
+	}
+
+	return Matrix[T]{
+		data:      t.data,
+		offset:    t.offset,
+		rows:      t.shape[0],
+		cols:      t.shape[1],
+		rowStride: t.strides[0],
+		colStride: t.strides[1],
+	}
+}
+
+// All returns an iterator over the elements in row major order
+func (t Tensor[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, val := range t.Cells() {
+			if !yield(val) {
+				return
+			}
+		}
+	}
+}
+
+// Cells returns an iterator over the index and element of every element in
+// row major order. The index slice is reused between elements, copy it to
+// keep it
+func (t Tensor[T]) Cells() iter.Seq2[[]int, T] {
+	return func(yield func([]int, T) bool) {
+		if t.Len() == 0 {
+			return
+		}
+
+		var idx [tensorMaxDims]int
+		for {
+			if !yield(idx[:t.dims], t.At(idx[:t.dims]...)) {
+				return
+			}
+
+			// count up the index, carrying into earlier dimensions
+			x := t.dims - 1
+			for ; x >= 0; x-- {
+				idx[x]++
+				if idx[x] < t.shape[x] {
+					break
+				}
+
+				idx[x] = 0
+			}
+
+			if x < 0 {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package alloc
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatrix(t *testing.T) {
+	arena := NewExpandingAllocator(8)
+	m := Must(NewMatrix[float64](&arena, 2, 3))
+	assert.Equal(t, []float64{0, 0, 0, 0, 0, 0}, slices.Collect(m.All()))
+
+	for r := range m.Rows() {
+		for c := range m.Cols() {
+			m.Set(r, c, float64(r*10+c))
+		}
+	}
+
+	assert.Equal(t, []float64{0, 1, 2, 10, 11, 12}, slices.Collect(m.All()))
+	assert.Equal(t, []float64{10, 11, 12}, slices.Collect(m.Row(1).All()))
+	assert.Equal(t, []float64{1, 11}, slices.Collect(m.Col(1).All()))
+
+	tr := m.T()
+	assert.Equal(t, 3, tr.Rows())
+	assert.Equal(t, 2, tr.Cols())
+	assert.Equal(t, []float64{0, 10, 1, 11, 2, 12}, slices.Collect(tr.All()))
+	assert.Equal(t, []float64{2, 12}, slices.Collect(tr.Row(2).All()))
+
+	// views share the same elements
+	tr.Row(2).Set(0, 1, 99)
+	assert.Equal(t, float64(99), m.At(1, 2))
+
+	v := m.View(0, 1, 2, 3)
+	assert.Equal(t, []float64{1, 2, 11, 99}, slices.Collect(v.All()))
+	for idx, val := range v.Cells() {
+		assert.Equal(t, m.At(idx[0], idx[1]+1), val)
+	}
+
+	assert.Panics(t, func() { m.At(2, 0) })
+	assert.Panics(t, func() { m.View(0, 0, 3, 1) })
+	assert.Panics(t, func() { MatrixFrom(m.data, 4, 4) })
+}
+
+func TestTensor(t *testing.T) {
+	arena := NewExpandingAllocator(8)
+	arr := Must(NewArray[int](&arena, 24))
+	for x := range arr.Deref().Slice() {
+		arr.Deref().Slice()[x] = x
+	}
+
+	ten := TensorFrom(*arr.Deref(), 2, 3, 4)
+	assert.Equal(t, []int{2, 3, 4}, ten.Shape())
+	assert.Equal(t, 24, ten.Len())
+	assert.Equal(t, 23, ten.At(1, 2, 3))
+	assert.Equal(t, 13, ten.At(1, 0, 1))
+
+	// indexing drops the first dimension
+	sub := ten.Index(1)
+	assert.Equal(t, []int{3, 4}, sub.Shape())
+	assert.Equal(t, []int{20, 21, 22, 23}, slices.Collect(sub.Index(2).All()))
+	assert.Equal(t, []int{13, 17, 21}, slices.Collect(sub.Matrix().Col(1).All()))
+
+	tr := ten.Transpose(2, 0, 1)
+	assert.Equal(t, []int{4, 2, 3}, tr.Shape())
+	assert.Equal(t, ten.At(1, 2, 3), tr.At(3, 1, 2))
+	assert.Equal(t, []int{4, 3, 2}, ten.Transpose().Shape())
+	assert.Equal(t, ten.At(1, 0, 2), ten.Transpose().At(2, 0, 1))
+
+	tr.Set(-1, 0, 0, 0)
+	assert.Equal(t, -1, ten.At(0, 0, 0))
+
+	var cells [][]int
+	for idx := range Must(NewTensor[int](&arena, 2, 2)).Cells() {
+		cells = append(cells, slices.Clone(idx))
+	}
+	assert.Equal(t, [][]int{{0, 0}, {0, 1}, {1, 0}, {1, 1}}, cells)
+
+	assert.Panics(t, func() { ten.At(0, 0) })
+	assert.Panics(t, func() { ten.Transpose(0, 0, 1) })
+	assert.Panics(t, func() { ten.Index(2) })
+}
@@ -0,0 +1,397 @@
+package alloc
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+var ErrInvalidMsgpack = errors.New("invalid msgpack")
+
+// msgpackMaxDepth limits how deeply arrays and maps can be nested so
+// malicious input can't exhaust the stack
+const msgpackMaxDepth = 512
+
+// DecodeMsgpack decodes a single MessagePack value from b into the allocator.
+// Maps become objects and must have string keys, str becomes a String, bin
+// becomes bytes, and every integer which fits in an int64 becomes an int.
+// Extension types are not supported. ErrInvalidMsgpack is returned if b is
+// not a single valid value.
+func DecodeMsgpack(a Allocator, b []byte) (Ptr[Value], error) {
+	d := msgpackDecoder{a: a, b: b}
+	v, err := d.decode(0)
+	if err != nil {
+		return Ptr[Value]{}, err
+	}
+
+	if d.pos != len(d.b) {
+		return Ptr[Value]{}, d.errorf("%d trailing bytes", len(d.b)-d.pos)
+	}
+
+	ptr, err := New[Value](a)
+	if err != nil {
+		return Ptr[Value]{}, err
+	}
+
+	ptr.Set(v)
+	return ptr, nil
+}
+
+// msgpackDecoder reads values from b, storing them in a
+type msgpackDecoder struct {
+	a   Allocator
+	b   []byte
+	pos int
+}
+
+func (d *msgpackDecoder) errorf(format string, args ...any) error {
+	return fmt.Errorf("%w: %s at offset %d", ErrInvalidMsgpack, fmt.Sprintf(format, args...), d.pos)
+}
+
+// next returns the next n bytes
+func (d *msgpackDecoder) next(n int) ([]byte, error) {
+	if n < 0 || len(d.b)-d.pos < n {
+		return nil, d.errorf("unexpected end of input")
+	}
+
+	b := d.b[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+// uint reads a big endian unsigned integer of size bytes
+func (d *msgpackDecoder) uint(size int) (uint64, error) {
+	b, err := d.next(size)
+	if err != nil {
+		return 0, err
+	}
+
+	switch size {
+	case 1:
+		return uint64(b[0]), nil
+	case 2:
+		return uint64(binary.BigEndian.Uint16(b)), nil
+	case 4:
+		return uint64(binary.BigEndian.Uint32(b)), nil
+	default:
+		return binary.BigEndian.Uint64(b), nil
+	}
+}
+
+// length reads a length prefix of size bytes
+func (d *msgpackDecoder) length(size int) (int, error) {
+	n, err := d.uint(size)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(n), nil
+}
+
+func (d *msgpackDecoder) decode(depth int) (Value, error) {
+	if depth > msgpackMaxDepth {
+		return Value{}, d.errorf("nested too deeply")
+	}
+
+	head, err := d.next(1)
+	if err != nil {
+		return Value{}, err
+	}
+
+	c := head[0]
+	switch {
+	case c <= 0x7f:
+		return IntValue(int64(c)), nil
+	case c >= 0xe0:
+		return IntValue(int64(int8(c))), nil
+	case c >= 0x80 && c <= 0x8f:
+		return d.object(int(c&0x0f), depth)
+	case c >= 0x90 && c <= 0x9f:
+		return d.array(int(c&0x0f), depth)
+	case c >= 0xa0 && c <= 0xbf:
+		return d.string(int(c & 0x1f))
+	}
+
+	switch c {
+	case 0xc0:
+		return NullValue(), nil
+	case 0xc2:
+		return BoolValue(false), nil
+	case 0xc3:
+		return BoolValue(true), nil
+	case 0xc4, 0xc5, 0xc6:
+		n, err := d.length(1 << (c - 0xc4))
+		if err != nil {
+			return Value{}, err
+		}
+
+		s, err := d.string(n)
+		if err != nil {
+			return Value{}, err
+		}
+
+		return BytesValue(Array[byte](s.Str())), nil
+	case 0xca:
+		n, err := d.uint(4)
+		if err != nil {
+			return Value{}, err
+		}
+
+		return FloatValue(float64(math.Float32frombits(uint32(n)))), nil
+	case 0xcb:
+		n, err := d.uint(8)
+		if err != nil {
+			return Value{}, err
+		}
+
+		return FloatValue(math.Float64frombits(n)), nil
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		n, err := d.uint(1 << (c - 0xcc))
+		if err != nil {
+			return Value{}, err
+		}
+
+		if n > math.MaxInt64 {
+			return UintValue(n), nil
+		}
+
+		return IntValue(int64(n)), nil
+	case 0xd0:
+		n, err := d.uint(1)
+		return IntValue(int64(int8(n))), err
+	case 0xd1:
+		n, err := d.uint(2)
+		return IntValue(int64(int16(n))), err
+	case 0xd2:
+		n, err := d.uint(4)
+		return IntValue(int64(int32(n))), err
+	case 0xd3:
+		n, err := d.uint(8)
+		return IntValue(int64(n)), err
+	case 0xd9, 0xda, 0xdb:
+		n, err := d.length(1 << (c - 0xd9))
+		if err != nil {
+			return Value{}, err
+		}
+
+		return d.string(n)
+	case 0xdc, 0xdd:
+		n, err := d.length(2 << (c - 0xdc))
+		if err != nil {
+			return Value{}, err
+		}
+
+		return d.array(n, depth)
+	case 0xde, 0xdf:
+		n, err := d.length(2 << (c - 0xde))
+		if err != nil {
+			return Value{}, err
+		}
+
+		return d.object(n, depth)
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8, 0xc7, 0xc8, 0xc9:
+		return Value{}, d.errorf("extension types are not supported")
+	default:
+		return Value{}, d.errorf("unknown type 0x%02x", c)
+	}
+}
+
+// string copies the next n bytes into a String in the allocator
+func (d *msgpackDecoder) string(n int) (Value, error) {
+	b, err := d.next(n)
+	if err != nil {
+		return Value{}, err
+	}
+
+	s, err := NewStringFromBytes(d.a, b)
+	if err != nil {
+		return Value{}, err
+	}
+
+	return StringValue(*s.Deref()), nil
+}
+
+func (d *msgpackDecoder) array(n int, depth int) (Value, error) {
+	// every element takes at least one byte, this stops us from allocating
+	// huge arrays for bad input
+	if n > len(d.b)-d.pos {
+		return Value{}, d.errorf("array length %d exceeds input", n)
+	}
+
+	arr, err := NewArray[Value](d.a, n)
+	if err != nil {
+		return Value{}, err
+	}
+
+	for x := range n {
+		v, err := d.decode(depth + 1)
+		if err != nil {
+			return Value{}, err
+		}
+
+		// the array header may have moved while decoding, so deref again
+		arr.Deref().ptr(x).Set(v)
+	}
+
+	return ArrayValue(*arr.Deref()), nil
+}
+
+func (d *msgpackDecoder) object(n int, depth int) (Value, error) {
+	// every entry takes at least two bytes
+	if n > (len(d.b)-d.pos)/2 {
+		return Value{}, d.errorf("map length %d exceeds input", n)
+	}
+
+	// the object is sized to fit every key, so it never has to grow
+	obj, err := NewObject[string, String, Value](d.a, n)
+	if err != nil {
+		return Value{}, err
+	}
+
+	for range n {
+		key, err := d.decode(depth + 1)
+		if err != nil {
+			return Value{}, err
+		}
+
+		if key.Kind() != KindString {
+			return Value{}, d.errorf("map keys must be strings, found %s", key.Kind())
+		}
+
+		val, err := d.decode(depth + 1)
+		if err != nil {
+			return Value{}, err
+		}
+
+		if err := obj.Deref().Set(key.Str(), val); err != nil {
+			return Value{}, err
+		}
+	}
+
+	return ObjectValue(obj), nil
+}
+
+// AppendMsgpack appends the MessagePack encoding of v to dst and returns the
+// extended buffer. Values are always written in their smallest form.
+func AppendMsgpack(dst []byte, v Value) []byte {
+	switch v.Kind() {
+	case KindNull:
+		return append(dst, 0xc0)
+	case KindBool:
+		if v.Bool() {
+			return append(dst, 0xc3)
+		}
+
+		return append(dst, 0xc2)
+	case KindInt:
+		i := v.Int()
+		if i >= 0 {
+			return appendMsgpackUint(dst, uint64(i))
+		}
+
+		return appendMsgpackInt(dst, i)
+	case KindUint:
+		return appendMsgpackUint(dst, v.Uint())
+	case KindFloat:
+		f := v.Float()
+		if float64(float32(f)) == f {
+			dst = append(dst, 0xca)
+			return binary.BigEndian.AppendUint32(dst, math.Float32bits(float32(f)))
+		}
+
+		dst = append(dst, 0xcb)
+		return binary.BigEndian.AppendUint64(dst, math.Float64bits(f))
+	case KindString:
+		s := v.Str()
+		n := Array[byte](s).Length()
+		switch {
+		case n < 32:
+			dst = append(dst, 0xa0|byte(n))
+		case n <= math.MaxUint8:
+			dst = append(dst, 0xd9, byte(n))
+		default:
+			dst = appendMsgpackLength(dst, 0xda, n)
+		}
+
+		return append(dst, s.Cast()...)
+	case KindBytes:
+		b := v.Bytes()
+		if b.Length() <= math.MaxUint8 {
+			dst = append(dst, 0xc4, byte(b.Length()))
+		} else {
+			dst = appendMsgpackLength(dst, 0xc5, b.Length())
+		}
+
+		return append(dst, b.Slice()...)
+	case KindArray:
+		arr := v.Array()
+		if arr.Length() < 16 {
+			dst = append(dst, 0x90|byte(arr.Length()))
+		} else {
+			dst = appendMsgpackLength(dst, 0xdc, arr.Length())
+		}
+
+		for x := range arr.Length() {
+			dst = AppendMsgpack(dst, *arr.ptr(x).Deref())
+		}
+
+		return dst
+	case KindObject:
+		obj := v.Object().Deref()
+		if obj.len < 16 {
+			dst = append(dst, 0x80|byte(obj.len))
+		} else {
+			dst = appendMsgpackLength(dst, 0xde, obj.len)
+		}
+
+		for x := range obj.len {
+			dst = AppendMsgpack(dst, StringValue(obj.keys.Slice()[x]))
+			dst = AppendMsgpack(dst, obj.vals.Slice()[x])
+		}
+
+		return dst
+	default:
+		panic("unknown value kind " + v.Kind().String())
+	}
+}
+
+// appendMsgpackLength writes the 16 bit form of the type c when n fits,
+// otherwise the 32 bit form which always directly follows it
+func appendMsgpackLength(dst []byte, c byte, n int) []byte {
+	if n <= math.MaxUint16 {
+		return binary.BigEndian.AppendUint16(append(dst, c), uint16(n))
+	}
+
+	return binary.BigEndian.AppendUint32(append(dst, c+1), uint32(n))
+}
+
+func appendMsgpackUint(dst []byte, u uint64) []byte {
+	switch {
+	case u <= 0x7f:
+		return append(dst, byte(u))
+	case u <= math.MaxUint8:
+		return append(dst, 0xcc, byte(u))
+	case u <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(dst, 0xcd), uint16(u))
+	case u <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(dst, 0xce), uint32(u))
+	default:
+		return binary.BigEndian.AppendUint64(append(dst, 0xcf), u)
+	}
+}
+
+func appendMsgpackInt(dst []byte, i int64) []byte {
+	switch {
+	case i >= -32:
+		return append(dst, byte(int8(i)))
+	case i >= math.MinInt8:
+		return append(dst, 0xd0, byte(int8(i)))
+	case i >= math.MinInt16:
+		return binary.BigEndian.AppendUint16(append(dst, 0xd1), uint16(int16(i)))
+	case i >= math.MinInt32:
+		return binary.BigEndian.AppendUint32(append(dst, 0xd2), uint32(int32(i)))
+	default:
+		return binary.BigEndian.AppendUint64(append(dst, 0xd3), uint64(i))
+	}
+}
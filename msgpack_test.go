@@ -0,0 +1,230 @@
+package alloc
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// msgpackFixtures are hand written encodings of each format in the
+// MessagePack specification, the files in testdata/msgpack are written by a
+// reference implementation.
+// canonical is the encoding we expect to write back, which is only set when
+// the fixture does not use the smallest form
+var msgpackFixtures = []struct {
+	name      string
+	hex       string
+	canonical string
+}{
+	{name: "nil", hex: "c0"},
+	{name: "false", hex: "c2"},
+	{name: "true", hex: "c3"},
+	{name: "positive fixint", hex: "7f"},
+	{name: "negative fixint", hex: "e0"},
+	{name: "uint8", hex: "ccff"},
+	{name: "uint16", hex: "cdffff"},
+	{name: "uint32", hex: "ceffffffff"},
+	{name: "uint64", hex: "cfffffffffffffffff"},
+	{name: "int8", hex: "d080"},
+	{name: "int16", hex: "d18000"},
+	{name: "int32", hex: "d280000000"},
+	{name: "int64", hex: "d38000000000000000"},
+	{name: "wide uint8", hex: "cc01", canonical: "01"},
+	{name: "wide int32", hex: "d2ffffffff", canonical: "ff"},
+	{name: "float32", hex: "ca3f000000"},
+	{name: "float64", hex: "cb3fb999999999999a"},
+	{name: "wide float64", hex: "cb3fe0000000000000", canonical: "ca3f000000"},
+	{name: "empty fixstr", hex: "a0"},
+	{name: "fixstr", hex: "a568656c6c6f"},
+	{name: "str8", hex: "d9" + "20" + strings.Repeat("61", 32)},
+	{name: "wide str8", hex: "d903616263", canonical: "a3616263"},
+	{name: "str16", hex: "da0100" + strings.Repeat("61", 256)},
+	{name: "bin8", hex: "c403010203"},
+	{name: "empty bin8", hex: "c400"},
+	{name: "wide bin16", hex: "c50001ff", canonical: "c401ff"},
+	{name: "empty fixarray", hex: "90"},
+	{name: "fixarray", hex: "93010203"},
+	{name: "array16", hex: "dc0010" + strings.Repeat("c0", 16)},
+	{name: "wide array16", hex: "dc0003010203", canonical: "93010203"},
+	{name: "empty fixmap", hex: "80"},
+	{name: "fixmap", hex: "82a16101a162c2"},
+	{name: "wide map16", hex: "de0001a161c0", canonical: "81a161c0"},
+	{name: "nested", hex: "82a5757365727392" + "81a46e616d65a5616c696365" + "de0001a46e616d65a3626f62" + "a5636f756e7402", canonical: "82a5757365727392" + "81a46e616d65a5616c696365" + "81a46e616d65a3626f62" + "a5636f756e7402"},
+}
+
+func TestMsgpackFixtures(t *testing.T) {
+	for _, fixture := range msgpackFixtures {
+		t.Run(fixture.name, func(t *testing.T) {
+			arena := NewExpandingAllocator(8)
+			in, _ := hex.DecodeString(fixture.hex)
+
+			v, err := DecodeMsgpack(&arena, in)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			want := fixture.canonical
+			if want == "" {
+				want = fixture.hex
+			}
+
+			assert.Equal(t, want, hex.EncodeToString(AppendMsgpack(nil, *v.Deref())))
+		})
+	}
+}
+
+// describeMsgpack turns a decoded Value into the form testdata/msgpack/gen
+// writes alongside each fixture
+func describeMsgpack(v Value) any {
+	switch v.Kind() {
+	case KindBool:
+		return map[string]any{"bool": v.Bool()}
+	case KindInt:
+		return map[string]any{"int": strconv.FormatInt(v.Int(), 10)}
+	case KindUint:
+		return map[string]any{"uint": strconv.FormatUint(v.Uint(), 10)}
+	case KindFloat:
+		return map[string]any{"float": strconv.FormatFloat(v.Float(), 'g', -1, 64)}
+	case KindString:
+		return map[string]any{"str": v.Str().String()}
+	case KindBytes:
+		return map[string]any{"bin": append([]byte{}, v.Bytes().Slice()...)}
+	case KindArray:
+		out := []any{}
+		for e := range v.Array().Iter() {
+			out = append(out, describeMsgpack(e))
+		}
+
+		return map[string]any{"array": out}
+	case KindObject:
+		out := []any{}
+		for k, e := range v.Object().Deref().Iter() {
+			out = append(out, []any{k.String(), describeMsgpack(e)})
+		}
+
+		return map[string]any{"map": out}
+	}
+
+	return nil
+}
+
+// TestMsgpackReference decodes fixtures written by a reference msgpack
+// implementation, see testdata/msgpack/gen
+func TestMsgpackReference(t *testing.T) {
+	files, _ := filepath.Glob("testdata/msgpack/*.msgpack")
+	if !assert.NotEmpty(t, files) {
+		return
+	}
+
+	for _, file := range files {
+		name := strings.TrimSuffix(strings.TrimSuffix(filepath.Base(file), ".msgpack"), ".compact")
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			in, err := os.ReadFile(file)
+			assert.NoError(t, err)
+			want, err := os.ReadFile(filepath.Join("testdata/msgpack", name+".json"))
+			assert.NoError(t, err)
+
+			arena := NewExpandingAllocator(pageSize)
+			v, err := DecodeMsgpack(&arena, in)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			got, _ := json.Marshal(describeMsgpack(*v.Deref()))
+			assert.JSONEq(t, string(want), string(got))
+
+			// our encoding has to decode to the same value
+			v, err = DecodeMsgpack(&arena, AppendMsgpack(nil, *v.Deref()))
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			got, _ = json.Marshal(describeMsgpack(*v.Deref()))
+			assert.JSONEq(t, string(want), string(got))
+		})
+	}
+}
+
+func TestMsgpackDecode(t *testing.T) {
+	arena := NewExpandingAllocator(8)
+	decode := func(h string) Value {
+		b, _ := hex.DecodeString(h)
+		return *Must(DecodeMsgpack(&arena, b)).Deref()
+	}
+
+	assert.Equal(t, int64(-32), decode("e0").Int())
+	assert.Equal(t, int64(255), decode("ccff").Int())
+	assert.Equal(t, uint64(math.MaxUint64), decode("cfffffffffffffffff").Uint())
+	assert.Equal(t, int64(math.MinInt64), decode("d38000000000000000").Int())
+	assert.Equal(t, 0.5, decode("ca3f000000").Float())
+	assert.Equal(t, "hello", decode("a568656c6c6f").Str().String())
+	assert.Equal(t, []byte{1, 2, 3}, decode("c403010203").Bytes().Slice())
+	assert.Equal(t, KindNull, decode("c0").Kind())
+
+	obj := decode("82a16101a162c2").Object().Deref()
+	a, _ := obj.Get("a")
+	assert.Equal(t, int64(1), a.Int())
+	b, _ := obj.Get("b")
+	assert.Equal(t, KindBool, b.Kind())
+	assert.Equal(t, false, b.Bool())
+}
+
+func TestMsgpackInvalid(t *testing.T) {
+	for _, h := range []string{
+		"",
+		"c1",
+		"cd00",
+		"a56865",
+		"930102",
+		"8101c0",
+		"d40100",
+		"c0c0",
+		"dfffffffff",
+		strings.Repeat("91", msgpackMaxDepth+2) + "c0",
+	} {
+		arena := NewExpandingAllocator(8)
+		b, _ := hex.DecodeString(h)
+		_, err := DecodeMsgpack(&arena, b)
+		assert.ErrorIs(t, err, ErrInvalidMsgpack, h)
+	}
+}
+
+// FuzzMsgpack checks that anything we can decode can be encoded, and that
+// the encoding is stable when decoded and encoded again
+func FuzzMsgpack(f *testing.F) {
+	for _, fixture := range msgpackFixtures {
+		b, _ := hex.DecodeString(fixture.hex)
+		f.Add(b)
+	}
+
+	files, _ := filepath.Glob("testdata/msgpack/*.msgpack")
+	for _, file := range files {
+		if b, err := os.ReadFile(file); err == nil {
+			f.Add(b)
+		}
+	}
+
+	f.Fuzz(func(t *testing.T, in []byte) {
+		arena := NewExpandingAllocator(pageSize)
+		v, err := DecodeMsgpack(&arena, in)
+		if err != nil {
+			return
+		}
+
+		first := AppendMsgpack(nil, *v.Deref())
+		v, err = DecodeMsgpack(&arena, first)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		second := AppendMsgpack(nil, *v.Deref())
+		assert.Equal(t, first, second)
+	})
+}
@@ -1,8 +1,12 @@
 package alloc
 
-import "iter"
+import (
+	"iter"
+	"unsafe"
+)
 
-// Object uses a linear search pattern to find the key specified.
+// Object uses a linear search pattern to find the key specified, or a
+// binary search once it has been sorted with SortObject.
 // C is the underlying primitive golang type. When you call Get or
 // iterate over the values, you likley want the golang primitive type, and
 // not the type stored in alloc.
@@ -18,6 +22,8 @@ type Object[C comparable, K Primitive[C], T any] struct {
 	keys Array[K]
 	vals Array[T]
 	len  int
+	// sorted is set by SortObject, and cleared when a new key is added
+	sorted bool
 }
 
 // NewObject creates a new object on the heap. The Allocator passed in will store the
@@ -45,13 +51,20 @@ func NewObject[C comparable, K Primitive[C], T any](alloc Allocator, size int) (
 	// uninitialized data means there could be garbage in this value,
 	// so we need to set the value to 0
 	obj.Deref().len = 0
+	obj.Deref().sorted = false
 
 	return obj, nil
 }
 
 // index will return the index the key was found at. If the key was not
-// found it will return -1
+// found it will return -1. Sorted objects are searched with a binary search
 func (m Object[C, K, T]) index(key C) int {
+	if m.sorted {
+		if x, ok := m.search(key); ok {
+			return x
+		}
+	}
+
 	for x, val := range m.keys.Slice() {
 		if x >= m.len {
 			break
@@ -70,28 +83,56 @@ func (m Object[C, K, T]) full() bool {
 	return m.len >= m.keys.Length()
 }
 
-// grow increases the size of the object when full
-func (m *Object[C, K, T]) grow() error {
-	newlen := m.len * 2
+// offset returns where the object is in the allocator's memory, and false
+// if it isn't in the allocator, like an Object held on the go stack
+func (m *Object[C, K, T]) offset() (uintptr, bool) {
+	a := m.keys.data.alloc
+	if a == nil {
+		return 0, false
+	}
+
+	// a pointer below the allocator's memory wraps around to a huge offset
+	offset := uintptr(unsafe.Pointer(m)) - uintptr(a.Offset(0))
+	if _, err := a.OffsetChecked(offset, unsafe.Sizeof(*m)); err != nil {
+		return 0, false
+	}
+
+	return offset, true
+}
+
+// grow doubles the capacity of the object when full. Growing allocates,
+// which can move the allocator's memory along with the object if it is
+// stored there, so the object is returned and m must no longer be used
+func (m *Object[C, K, T]) grow() (*Object[C, K, T], error) {
+	newlen := m.keys.Length() * 2
 	if newlen == 0 {
 		newlen = 10
 	}
 
+	a := m.keys.data.alloc
+	offset, inAlloc := m.offset()
+	reload := func() {
+		if inAlloc {
+			m = (*Object[C, K, T])(a.Offset(offset))
+		}
+	}
+
 	keys, err := m.keys.Expand(newlen)
 	if err != nil {
-		return err
+		return m, err
 	}
+	reload()
 
 	vals, err := m.vals.Expand(newlen)
 	if err != nil {
-		return err
+		return m, err
 	}
+	reload()
 
-	m.len = newlen
 	m.keys = keys
 	m.vals = vals
 
-	return nil
+	return m, nil
 }
 
 // Set stores a value in the object. It will check to make sure there is enough space
@@ -107,20 +148,24 @@ func (m *Object[C, K, T]) Set(key K, val T) error {
 
 	// make sure we can fit this new value into the object
 	if m.full() {
-		err := m.grow()
+		grown, err := m.grow()
 		if err != nil {
 			return err
 		}
+
+		m = grown
 	}
 
 	m.keys.Slice()[m.len] = key
 	m.vals.Slice()[m.len] = val
 	m.len++
+	m.sorted = false
 	return nil
 }
 
 // Get returns the value from the map, if no value exists we return the empty
-// value of T and false
+// value of T and false. Once the object is sorted with SortObject the key
+// is found with a binary search, until a new key is added
 func (m Object[C, K, T]) Get(key C) (T, bool) {
 	index := m.index(key)
 	if index == -1 {
@@ -130,12 +175,51 @@ func (m Object[C, K, T]) Get(key C) (T, bool) {
 	return m.vals.Slice()[index], true
 }
 
+// Has returns if the key exists in the object
+func (m Object[C, K, T]) Has(key C) bool {
+	return m.index(key) != -1
+}
+
+// Len returns the number of entries in the object
+func (m Object[C, K, T]) Len() int {
+	return m.len
+}
+
+// Sorted returns if the entries are sorted by key, see SortObject
+func (m Object[C, K, T]) Sorted() bool {
+	return m.sorted
+}
+
+// Delete removes the key from the object, returning the value which was
+// stored and true. If the key doesn't exist the empty value of T and false
+// are returned. The order of the remaining entries is kept.
+func (m *Object[C, K, T]) Delete(key C) (T, bool) {
+	index := m.index(key)
+	if index == -1 {
+		return *new(T), false
+	}
+
+	keys, vals := m.keys.Slice(), m.vals.Slice()
+	val := vals[index]
+	copy(keys[index:], keys[index+1:m.len])
+	copy(vals[index:], vals[index+1:m.len])
+	m.len--
+
+	return val, true
+}
+
+// Clear removes every entry from the object, the space is kept for new
+// entries
+func (m *Object[C, K, T]) Clear() {
+	m.len = 0
+}
+
 // Iter returns an iterator for the object enabling you to use this in a
 // for each (range). The key will be the first value, and the type will be
 // the second.
 func (m Object[C, K, T]) Iter() iter.Seq2[K, T] {
 	return func(yield func(K, T) bool) {
-		for x, key := range m.keys.Slice() {
+		for x, key := range m.keys.Slice()[:m.len] {
 			val := m.vals.Slice()[x]
 			if !yield(key, val) {
 				return
@@ -144,12 +228,11 @@ func (m Object[C, K, T]) Iter() iter.Seq2[K, T] {
 	}
 }
 
-// Iter returns an iterator for the object enabling you to use this in a
-// for each (range). The key will be the first value, and the type will be
-// the second.
+// IterPrimitive returns an iterator for the object like Iter, but the keys
+// are returned as primitives
 func (m Object[C, K, T]) IterPrimitive() iter.Seq2[C, T] {
 	return func(yield func(C, T) bool) {
-		for x, key := range m.keys.Slice() {
+		for x, key := range m.keys.Slice()[:m.len] {
 			val := m.vals.Slice()[x]
 			if !yield(key.Cast(), val) {
 				return
@@ -158,10 +241,10 @@ func (m Object[C, K, T]) IterPrimitive() iter.Seq2[C, T] {
 	}
 }
 
-// Keys returns an interator of key values as primitives
+// PrimitiveKeys returns an interator of key values as primitives
 func (m Object[C, K, T]) PrimitiveKeys() iter.Seq[C] {
 	return func(yield func(C) bool) {
-		for _, key := range m.keys.Slice() {
+		for _, key := range m.keys.Slice()[:m.len] {
 			if !yield(key.Cast()) {
 				return
 			}
@@ -172,7 +255,7 @@ func (m Object[C, K, T]) PrimitiveKeys() iter.Seq[C] {
 // Keys returns an interator of key values
 func (m Object[C, K, T]) Keys() iter.Seq[K] {
 	return func(yield func(K) bool) {
-		for _, key := range m.keys.Slice() {
+		for _, key := range m.keys.Slice()[:m.len] {
 			if !yield(key) {
 				return
 			}
@@ -180,10 +263,10 @@ func (m Object[C, K, T]) Keys() iter.Seq[K] {
 	}
 }
 
-// Values returns an interator of values
+// Vals returns an interator of values
 func (m Object[C, K, T]) Vals() iter.Seq[T] {
 	return func(yield func(T) bool) {
-		for _, val := range m.vals.Slice() {
+		for _, val := range m.vals.Slice()[:m.len] {
 			if !yield(val) {
 				return
 			}
@@ -90,3 +90,78 @@ func TestObject(t *testing.T) {
 		x++
 	}
 }
+
+func TestObjectDelete(t *testing.T) {
+	arena := NewExpandingAllocator(pageSize)
+	obj, err := NewObject[string, String, int](&arena, 2)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// setting more than the initial size grows the object
+	for x := range 5 {
+		s, err := NewString(&arena, strconv.Itoa(x))
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		assert.NoError(t, obj.Deref().Set(*s.Deref(), x))
+	}
+
+	assert.Equal(t, 5, obj.Deref().Len())
+	assert.True(t, obj.Deref().Has("4"))
+
+	val, ok := obj.Deref().Delete("1")
+	assert.Equal(t, 1, val)
+	assert.True(t, ok)
+	assert.False(t, obj.Deref().Has("1"))
+
+	_, ok = obj.Deref().Delete("1")
+	assert.False(t, ok)
+
+	// iteration only includes live entries, in insertion order
+	var keys []string
+	var vals []int
+	for key, val := range obj.Deref().IterPrimitive() {
+		keys = append(keys, key)
+		vals = append(vals, val)
+	}
+
+	assert.Equal(t, []string{"0", "2", "3", "4"}, keys)
+	assert.Equal(t, []int{0, 2, 3, 4}, vals)
+	assert.Equal(t, 4, obj.Deref().Len())
+
+	obj.Deref().Clear()
+	assert.Equal(t, 0, obj.Deref().Len())
+	assert.False(t, obj.Deref().Has("0"))
+	for range obj.Deref().Keys() {
+		assert.Fail(t, "cleared object should have no keys")
+	}
+}
+
+func TestObjectGrowMovesAllocator(t *testing.T) {
+	// the object is stored in an allocator small enough that growing it
+	// moves the allocator's memory, and the object along with it
+	arena := NewExpandingAllocator(64)
+	obj := Must(NewObject[int, Int, int](&arena, 1))
+	for x := range 50 {
+		assert.NoError(t, obj.Deref().Set(Int(x), x))
+	}
+
+	assert.Equal(t, 50, obj.Deref().Len())
+	for x := range 50 {
+		v, ok := obj.Deref().Get(x)
+		assert.True(t, ok, x)
+		assert.Equal(t, x, v)
+	}
+
+	// an object held outside the allocator grows the same way
+	local := *Must(NewObject[int, Int, int](&arena, 1)).Deref()
+	for x := range 50 {
+		assert.NoError(t, local.Set(Int(x), x))
+	}
+
+	assert.Equal(t, 50, local.Len())
+	v, _ := local.Get(49)
+	assert.Equal(t, 49, v)
+}
@@ -5,14 +5,15 @@ import "unsafe"
 const pageSize = 4096
 
 // PageAllocator is an allocator with only 4096 bytes. This is the
-// size of a page in linux. It will return ErrMemoryExhausted when full
+// size of a page in linux. It will return an *AllocError matching
+// ErrMemoryExhausted when full
 type PageAllocator struct {
 	ref uintptr
 	b   [pageSize]byte
 }
 
 // ensure we implement the allocator
-var _ Allocator = &PageAllocator{}
+var _ ResetAllocator = &PageAllocator{}
 
 // NewPageAllocator will create a new page allocator
 func NewPageAllocator() PageAllocator {
@@ -21,13 +22,19 @@ func NewPageAllocator() PageAllocator {
 
 // Alloc reserves the location in memory and returns the offset the
 // new allocation occured at. If the page can not fit the size required
-// ErrMemoryExhausted is returned.
+// an *AllocError is returned.
 func (a *PageAllocator) Alloc(size uintptr, alignment uintptr) (uintptr, error) {
 	start := align(a.ref, alignment)
 	end := start + size
 
-	if pageSize < int(end) {
-		return 0, ErrMemoryExhausted
+	// a huge size can wrap end around, so check it didn't go backwards
+	if end < start || end > pageSize {
+		return 0, &AllocError{
+			Size:      size,
+			Alignment: alignment,
+			Available: a.Available(),
+			Allocator: "PageAllocator",
+		}
 	}
 
 	a.ref = end
@@ -39,6 +46,20 @@ func (a *PageAllocator) Offset(offset uintptr) unsafe.Pointer {
 	return unsafe.Pointer(&(a.b)[offset])
 }
 
+// OffsetChecked returns the pointer to the offset supplied, or
+// ErrOutOfRange if size bytes at offset haven't all been allocated
+func (a *PageAllocator) OffsetChecked(offset, size uintptr) (unsafe.Pointer, error) {
+	if err := checkRange(offset, size, a.ref); err != nil {
+		return nil, err
+	}
+
+	if size == 0 {
+		return unsafe.Pointer(&zeroSized), nil
+	}
+
+	return a.Offset(offset), nil
+}
+
 // Available returns the amount of memory left in the page which can
 // be allocated to.
 func (a *PageAllocator) Available() uintptr {
@@ -0,0 +1,591 @@
+package alloc
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"iter"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidPath = errors.New("invalid path")
+
+// Path is a compiled query which can be evaluated over a tree of Values. The
+// syntax is a small subset of JSONPath
+//
+//	$                   the root value
+//	.name or ['name']   the member of an object
+//	[0] or [-1]         the element of an array, negative counts from the end
+//	.* or [*]           every member of an object or element of an array
+//	..name              name at any depth below the current value
+//	[?(@.age > 30)]     every child matching the filter
+//
+// Filters compare a value relative to the child (@) with a literal using
+// ==, !=, <, <=, > or >=. Literals can be numbers, 'strings', "strings",
+// true, false or null. A filter with no comparison, like [?(@.name)], matches
+// every child where the value exists.
+//
+// Compiling a path allocates on the heap, so you should compile paths once
+// and reuse them. Evaluating the path does not allocate.
+type Path struct {
+	expr  string
+	steps []pathStep
+}
+
+type stepKind uint8
+
+const (
+	stepName stepKind = iota
+	stepIndex
+	stepWildcard
+	stepFilter
+)
+
+// pathStep is a single selector within the path
+type pathStep struct {
+	kind stepKind
+	// descend applies the selector to the value and every value below it
+	descend bool
+	name    string
+	index   int
+	filter  pathFilter
+}
+
+type filterOp uint8
+
+const (
+	opExists filterOp = iota
+	opEq
+	opNe
+	opLt
+	opLe
+	opGt
+	opGe
+)
+
+// pathFilter compares the value found at path with the literal
+type pathFilter struct {
+	path []pathStep
+	op   filterOp
+	lit  Value
+	// str holds the literal string, since we can't store it in the
+	// allocator
+	str string
+}
+
+// CompilePath parses the expression into a Path. ErrInvalidPath is returned
+// when the expression can not be parsed
+func CompilePath(expr string) (Path, error) {
+	p := pathParser{expr: expr}
+	if !p.consume('$') {
+		return Path{}, p.errorf("path must start with $")
+	}
+
+	steps, err := p.steps(false)
+	if err != nil {
+		return Path{}, err
+	}
+
+	if p.pos != len(p.expr) {
+		return Path{}, p.errorf("unexpected character %q", p.expr[p.pos])
+	}
+
+	return Path{expr: expr, steps: steps}, nil
+}
+
+// MustCompilePath is like CompilePath but panics if the expression can not
+// be parsed
+func MustCompilePath(expr string) Path {
+	return Must(CompilePath(expr))
+}
+
+// Query compiles the expression and evaluates it over root, see Path for the
+// syntax
+func Query(root Ptr[Value], expr string) (iter.Seq[Ptr[Value]], error) {
+	p, err := CompilePath(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.Query(root), nil
+}
+
+// String returns the expression the path was compiled from
+func (p Path) String() string {
+	return p.expr
+}
+
+// Query evaluates the path over root and returns an iterator of every value
+// matched. The values are returned as Ptrs so they can be modified or kept
+// around after the query is done
+func (p Path) Query(root Ptr[Value]) iter.Seq[Ptr[Value]] {
+	return func(yield func(Ptr[Value]) bool) {
+		p.eval(root, 0, yield)
+	}
+}
+
+// First returns the first value matched by the path, false is returned if
+// nothing matched
+func (p Path) First(root Ptr[Value]) (Ptr[Value], bool) {
+	for v := range p.Query(root) {
+		return v, true
+	}
+
+	return Ptr[Value]{}, false
+}
+
+// eval applies step i to v, and continues with the next step for every
+// value selected. false is returned when yield asks us to stop
+func (p Path) eval(v Ptr[Value], i int, yield func(Ptr[Value]) bool) bool {
+	if i == len(p.steps) {
+		return yield(v)
+	}
+
+	step := &p.steps[i]
+	if !p.selectStep(v, step, i, yield) {
+		return false
+	}
+
+	if step.descend {
+		// apply the same step to every child, which will in turn apply it
+		// to their children
+		for x := 0; x < childCount(v); x++ {
+			if !p.eval(childAt(v, x), i, yield) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// selectStep finds the values step selects from v and evaluates the rest
+// of the path on them
+func (p Path) selectStep(v Ptr[Value], step *pathStep, i int, yield func(Ptr[Value]) bool) bool {
+	switch step.kind {
+	case stepName:
+		child, ok := member(v, step.name)
+		if !ok {
+			return true
+		}
+
+		return p.eval(child, i+1, yield)
+	case stepIndex:
+		child, ok := element(v, step.index)
+		if !ok {
+			return true
+		}
+
+		return p.eval(child, i+1, yield)
+	case stepWildcard:
+		for x := 0; x < childCount(v); x++ {
+			if !p.eval(childAt(v, x), i+1, yield) {
+				return false
+			}
+		}
+	case stepFilter:
+		for x := 0; x < childCount(v); x++ {
+			child := childAt(v, x)
+			if !step.filter.match(child) {
+				continue
+			}
+
+			if !p.eval(child, i+1, yield) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// member returns the value stored under key when v is an object
+func member(v Ptr[Value], key string) (Ptr[Value], bool) {
+	val := v.Deref()
+	if val.kind != KindObject {
+		return Ptr[Value]{}, false
+	}
+
+	obj := val.obj.Deref()
+	index := obj.index(key)
+	if index == -1 {
+		return Ptr[Value]{}, false
+	}
+
+	return obj.vals.ptr(index), true
+}
+
+// element returns the value at index when v is an array. Negative indexes
+// count back from the end of the array
+func element(v Ptr[Value], index int) (Ptr[Value], bool) {
+	val := v.Deref()
+	if val.kind != KindArray {
+		return Ptr[Value]{}, false
+	}
+
+	if index < 0 {
+		index += val.arr.Length()
+	}
+
+	if index < 0 || index >= val.arr.Length() {
+		return Ptr[Value]{}, false
+	}
+
+	return val.arr.ptr(index), true
+}
+
+// childCount returns the number of values directly below v
+func childCount(v Ptr[Value]) int {
+	val := v.Deref()
+	switch val.kind {
+	case KindArray:
+		return val.arr.Length()
+	case KindObject:
+		return val.obj.Deref().len
+	default:
+		return 0
+	}
+}
+
+// childAt returns the child at index x, the index must be below childCount
+func childAt(v Ptr[Value], x int) Ptr[Value] {
+	val := v.Deref()
+	if val.kind == KindArray {
+		return val.arr.ptr(x)
+	}
+
+	return val.obj.Deref().vals.ptr(x)
+}
+
+// match returns if v passes the filter
+func (f *pathFilter) match(v Ptr[Value]) bool {
+	for x := range f.path {
+		var ok bool
+		switch f.path[x].kind {
+		case stepName:
+			v, ok = member(v, f.path[x].name)
+		case stepIndex:
+			v, ok = element(v, f.path[x].index)
+		}
+
+		if !ok {
+			return false
+		}
+	}
+
+	if f.op == opExists {
+		return true
+	}
+
+	val := v.Deref()
+	switch f.lit.kind {
+	case KindFloat:
+		n, ok := val.number()
+		if !ok {
+			return f.op == opNe
+		}
+
+		return f.op.ordered(cmp.Compare(n, f.lit.Float()))
+	case KindString:
+		if val.kind != KindString {
+			return f.op == opNe
+		}
+
+		return f.op.ordered(strings.Compare(val.str.Cast(), f.str))
+	case KindBool:
+		return f.op.equal(val.kind == KindBool && val.Bool() == f.lit.Bool())
+	default:
+		return f.op.equal(val.kind == KindNull)
+	}
+}
+
+// ordered returns the result of the operator given the result of a
+// comparison
+func (op filterOp) ordered(c int) bool {
+	switch op {
+	case opEq:
+		return c == 0
+	case opNe:
+		return c != 0
+	case opLt:
+		return c < 0
+	case opLe:
+		return c <= 0
+	case opGt:
+		return c > 0
+	case opGe:
+		return c >= 0
+	default:
+		return false
+	}
+}
+
+// equal returns the result of the operator for values which can only be
+// checked for equality
+func (op filterOp) equal(eq bool) bool {
+	switch op {
+	case opEq:
+		return eq
+	case opNe:
+		return !eq
+	default:
+		return false
+	}
+}
+
+// pathParser turns the expression into steps
+type pathParser struct {
+	expr string
+	pos  int
+}
+
+func (p *pathParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("%w: %s at offset %d in %q", ErrInvalidPath, fmt.Sprintf(format, args...), p.pos, p.expr)
+}
+
+// peek returns if the next character is c
+func (p *pathParser) peek(c byte) bool {
+	return p.pos < len(p.expr) && p.expr[p.pos] == c
+}
+
+// consume moves past the next character if it is c
+func (p *pathParser) consume(c byte) bool {
+	if !p.peek(c) {
+		return false
+	}
+
+	p.pos++
+	return true
+}
+
+func (p *pathParser) skipSpace() {
+	for p.peek(' ') {
+		p.pos++
+	}
+}
+
+// steps parses selectors until it finds a character which can't start one.
+// When singular is true only names and indexes are allowed, which is used
+// for the paths within filters
+func (p *pathParser) steps(singular bool) ([]pathStep, error) {
+	var steps []pathStep
+	for p.pos < len(p.expr) {
+		var step pathStep
+		switch {
+		case p.consume('.'):
+			if p.consume('.') {
+				step.descend = true
+			}
+
+			switch {
+			case step.descend && p.peek('['):
+				if err := p.bracket(&step); err != nil {
+					return nil, err
+				}
+			case p.consume('*'):
+				step.kind = stepWildcard
+			default:
+				step.kind = stepName
+				step.name = p.name()
+				if step.name == "" {
+					return nil, p.errorf("expected a name")
+				}
+			}
+		case p.peek('['):
+			if err := p.bracket(&step); err != nil {
+				return nil, err
+			}
+		default:
+			return steps, nil
+		}
+
+		if singular && (step.descend || (step.kind != stepName && step.kind != stepIndex)) {
+			return nil, p.errorf("filters can only select names and indexes")
+		}
+
+		steps = append(steps, step)
+	}
+
+	return steps, nil
+}
+
+// bracket parses a selector wrapped in []
+func (p *pathParser) bracket(step *pathStep) error {
+	p.pos++
+	p.skipSpace()
+
+	switch {
+	case p.consume('*'):
+		step.kind = stepWildcard
+	case p.peek('\'') || p.peek('"'):
+		name, err := p.quoted()
+		if err != nil {
+			return err
+		}
+
+		step.kind = stepName
+		step.name = name
+	case p.consume('?'):
+		step.kind = stepFilter
+		if err := p.filter(&step.filter); err != nil {
+			return err
+		}
+	default:
+		start := p.pos
+		p.consume('-')
+		for p.pos < len(p.expr) && p.expr[p.pos] >= '0' && p.expr[p.pos] <= '9' {
+			p.pos++
+		}
+
+		index, err := strconv.Atoi(p.expr[start:p.pos])
+		if err != nil {
+			return p.errorf("expected an index")
+		}
+
+		step.kind = stepIndex
+		step.index = index
+	}
+
+	p.skipSpace()
+	if !p.consume(']') {
+		return p.errorf("expected ]")
+	}
+
+	return nil
+}
+
+// filter parses the expression following a ?
+func (p *pathParser) filter(f *pathFilter) error {
+	p.skipSpace()
+	paren := p.consume('(')
+	p.skipSpace()
+
+	if !p.consume('@') {
+		return p.errorf("filter must start with @")
+	}
+
+	path, err := p.steps(true)
+	if err != nil {
+		return err
+	}
+	f.path = path
+
+	p.skipSpace()
+	f.op = p.operator()
+	if f.op != opExists {
+		p.skipSpace()
+		if err := p.literal(f); err != nil {
+			return err
+		}
+	}
+
+	p.skipSpace()
+	if paren && !p.consume(')') {
+		return p.errorf("expected )")
+	}
+
+	return nil
+}
+
+// operator parses the comparison operator, opExists is returned when there
+// is no operator
+func (p *pathParser) operator() filterOp {
+	ops := [...]struct {
+		token string
+		op    filterOp
+	}{
+		{"==", opEq},
+		{"!=", opNe},
+		{"<=", opLe},
+		{">=", opGe},
+		{"<", opLt},
+		{">", opGt},
+	}
+
+	for _, o := range ops {
+		if strings.HasPrefix(p.expr[p.pos:], o.token) {
+			p.pos += len(o.token)
+			return o.op
+		}
+	}
+
+	return opExists
+}
+
+// literal parses the value a filter compares against
+func (p *pathParser) literal(f *pathFilter) error {
+	rest := p.expr[p.pos:]
+	switch {
+	case p.peek('\'') || p.peek('"'):
+		s, err := p.quoted()
+		if err != nil {
+			return err
+		}
+
+		f.lit = Value{kind: KindString}
+		f.str = s
+		return nil
+	case strings.HasPrefix(rest, "true"):
+		p.pos += len("true")
+		f.lit = BoolValue(true)
+		return nil
+	case strings.HasPrefix(rest, "false"):
+		p.pos += len("false")
+		f.lit = BoolValue(false)
+		return nil
+	case strings.HasPrefix(rest, "null"):
+		p.pos += len("null")
+		f.lit = NullValue()
+		return nil
+	}
+
+	start := p.pos
+	for p.pos < len(p.expr) && strings.IndexByte("+-.0123456789eE", p.expr[p.pos]) != -1 {
+		p.pos++
+	}
+
+	n, err := strconv.ParseFloat(p.expr[start:p.pos], 64)
+	if err != nil {
+		p.pos = start
+		return p.errorf("expected a literal")
+	}
+
+	f.lit = FloatValue(n)
+	return nil
+}
+
+// name parses an unquoted member name
+func (p *pathParser) name() string {
+	start := p.pos
+	for p.pos < len(p.expr) && strings.IndexByte(".[] =!<>()", p.expr[p.pos]) == -1 {
+		p.pos++
+	}
+
+	return p.expr[start:p.pos]
+}
+
+// quoted parses a string wrapped in single or double quotes. A backslash
+// escapes the next character
+func (p *pathParser) quoted() (string, error) {
+	quote := p.expr[p.pos]
+	p.pos++
+
+	var b strings.Builder
+	for p.pos < len(p.expr) {
+		c := p.expr[p.pos]
+		p.pos++
+
+		switch {
+		case c == quote:
+			return b.String(), nil
+		case c == '\\' && p.pos < len(p.expr):
+			b.WriteByte(p.expr[p.pos])
+			p.pos++
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return "", p.errorf("unterminated string")
+}
@@ -0,0 +1,113 @@
+package alloc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testUsers builds the tree
+//
+//	{"users": [{"name": "alice", "age": 30}, {"name": "bob", "age": 25}], "count": 2}
+func testUsers(a Allocator) Ptr[Value] {
+	user := func(name string, age int64) Value {
+		obj := Must(NewObject[string, String, Value](a, 2))
+		nameKey := *Must(NewString(a, "name")).Deref()
+		nameVal := *Must(NewString(a, name)).Deref()
+		ageKey := *Must(NewString(a, "age")).Deref()
+
+		obj.Deref().Set(nameKey, StringValue(nameVal))
+		obj.Deref().Set(ageKey, IntValue(age))
+		return ObjectValue(obj)
+	}
+
+	users := Must(NewArray[Value](a, 2))
+	alice := user("alice", 30)
+	bob := user("bob", 25)
+	users.Deref().Slice()[0] = alice
+	users.Deref().Slice()[1] = bob
+
+	root := Must(NewObject[string, String, Value](a, 2))
+	usersKey := *Must(NewString(a, "users")).Deref()
+	countKey := *Must(NewString(a, "count")).Deref()
+	root.Deref().Set(usersKey, ArrayValue(*users.Deref()))
+	root.Deref().Set(countKey, UintValue(2))
+
+	v := Must(New[Value](a))
+	v.Set(ObjectValue(root))
+	return v
+}
+
+// queryStrings returns the string values matched by the path
+func queryStrings(t *testing.T, root Ptr[Value], expr string) []string {
+	seq, err := Query(root, expr)
+	if !assert.NoError(t, err) {
+		return nil
+	}
+
+	var out []string
+	for v := range seq {
+		out = append(out, v.Deref().Str().String())
+	}
+
+	return out
+}
+
+func TestPath(t *testing.T) {
+	arena := NewExpandingAllocator(8)
+	root := testUsers(&arena)
+
+	assert.Equal(t, []string{"alice", "bob"}, queryStrings(t, root, "$.users[*].name"))
+	assert.Equal(t, []string{"alice", "bob"}, queryStrings(t, root, "$['users'][*]['name']"))
+	assert.Equal(t, []string{"bob"}, queryStrings(t, root, "$.users[-1].name"))
+	assert.Equal(t, []string{"alice", "bob"}, queryStrings(t, root, "$..name"))
+	assert.Equal(t, []string{"alice"}, queryStrings(t, root, "$.users[?(@.age > 25)].name"))
+	assert.Equal(t, []string{"bob"}, queryStrings(t, root, "$.users[?(@.age <= 25)].name"))
+	assert.Equal(t, []string{"bob"}, queryStrings(t, root, "$.users[?(@.name == 'bob')].name"))
+	assert.Equal(t, []string{"alice"}, queryStrings(t, root, `$.users[?@.name != "bob"].name`))
+	assert.Equal(t, []string{"alice", "bob"}, queryStrings(t, root, "$.users[?(@.age)].name"))
+	assert.Empty(t, queryStrings(t, root, "$.users[?(@.missing)].name"))
+	assert.Empty(t, queryStrings(t, root, "$.users[5].name"))
+
+	count, ok := MustCompilePath("$.count").First(root)
+	if assert.True(t, ok) {
+		assert.Equal(t, uint64(2), count.Deref().Uint())
+	}
+
+	// values are returned as pointers so they can be updated in place
+	age, ok := MustCompilePath("$.users[0].age").First(root)
+	if assert.True(t, ok) {
+		age.Set(IntValue(31))
+	}
+	assert.Equal(t, []string{"alice"}, queryStrings(t, root, "$.users[?(@.age == 31)].name"))
+}
+
+func TestPathInvalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"users",
+		"$.",
+		"$[",
+		"$[abc]",
+		"$['abc",
+		"$[?(@.a == )]",
+		"$[?(@.a[*] == 1)]",
+		"$.a)",
+	} {
+		_, err := CompilePath(expr)
+		assert.ErrorIs(t, err, ErrInvalidPath, expr)
+	}
+}
+
+func BenchmarkPath(b *testing.B) {
+	arena := NewExpandingAllocator(pageSize)
+	root := testUsers(&arena)
+	path := MustCompilePath("$.users[?(@.age > 25)].name")
+
+	b.ReportAllocs()
+	for b.Loop() {
+		for v := range path.Query(root) {
+			_ = v
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package alloc
+
+import "cmp"
+
+// The types below wrap every go type which satisfies cmp.Ordered, other than
+// string which is stored as a String. Each implements Primitive and
+// Comparable, so it can be used as a key in an Object, BTree or SkipList
+//
+//	tree := alloc.Must(alloc.NewBTree[alloc.Uint16, string](&arena))
+//	tree.Set(alloc.Uint16(port), name)
+
+// Int is an int which implements Primitive and Comparable
+type Int int
+
+// Cast returns the value as an int
+func (i Int) Cast() int {
+	return int(i)
+}
+
+// Cmp compares the value with val
+func (i Int) Cmp(val Int) int {
+	return cmp.Compare(i, val)
+}
+
+// Int8 is an int8 which implements Primitive and Comparable
+type Int8 int8
+
+// Cast returns the value as an int8
+func (i Int8) Cast() int8 {
+	return int8(i)
+}
+
+// Cmp compares the value with val
+func (i Int8) Cmp(val Int8) int {
+	return cmp.Compare(i, val)
+}
+
+// Int16 is an int16 which implements Primitive and Comparable
+type Int16 int16
+
+// Cast returns the value as an int16
+func (i Int16) Cast() int16 {
+	return int16(i)
+}
+
+// Cmp compares the value with val
+func (i Int16) Cmp(val Int16) int {
+	return cmp.Compare(i, val)
+}
+
+// Int32 is an int32 which implements Primitive and Comparable
+type Int32 int32
+
+// Cast returns the value as an int32
+func (i Int32) Cast() int32 {
+	return int32(i)
+}
+
+// Cmp compares the value with val
+func (i Int32) Cmp(val Int32) int {
+	return cmp.Compare(i, val)
+}
+
+// Int64 is an int64 which implements Primitive and Comparable
+type Int64 int64
+
+// Cast returns the value as an int64
+func (i Int64) Cast() int64 {
+	return int64(i)
+}
+
+// Cmp compares the value with val
+func (i Int64) Cmp(val Int64) int {
+	return cmp.Compare(i, val)
+}
+
+// Uint is a uint which implements Primitive and Comparable
+type Uint uint
+
+// Cast returns the value as a uint
+func (u Uint) Cast() uint {
+	return uint(u)
+}
+
+// Cmp compares the value with val
+func (u Uint) Cmp(val Uint) int {
+	return cmp.Compare(u, val)
+}
+
+// Uint8 is a uint8 which implements Primitive and Comparable
+type Uint8 uint8
+
+// Cast returns the value as a uint8
+func (u Uint8) Cast() uint8 {
+	return uint8(u)
+}
+
+// Cmp compares the value with val
+func (u Uint8) Cmp(val Uint8) int {
+	return cmp.Compare(u, val)
+}
+
+// Uint16 is a uint16 which implements Primitive and Comparable
+type Uint16 uint16
+
+// Cast returns the value as a uint16
+func (u Uint16) Cast() uint16 {
+	return uint16(u)
+}
+
+// Cmp compares the value with val
+func (u Uint16) Cmp(val Uint16) int {
+	return cmp.Compare(u, val)
+}
+
+// Uint32 is a uint32 which implements Primitive and Comparable
+type Uint32 uint32
+
+// Cast returns the value as a uint32
+func (u Uint32) Cast() uint32 {
+	return uint32(u)
+}
+
+// Cmp compares the value with val
+func (u Uint32) Cmp(val Uint32) int {
+	return cmp.Compare(u, val)
+}
+
+// Uint64 is a uint64 which implements Primitive and Comparable
+type Uint64 uint64
+
+// Cast returns the value as a uint64
+func (u Uint64) Cast() uint64 {
+	return uint64(u)
+}
+
+// Cmp compares the value with val
+func (u Uint64) Cmp(val Uint64) int {
+	return cmp.Compare(u, val)
+}
+
+// Uintptr is a uintptr which implements Primitive and Comparable
+type Uintptr uintptr
+
+// Cast returns the value as a uintptr
+func (u Uintptr) Cast() uintptr {
+	return uintptr(u)
+}
+
+// Cmp compares the value with val
+func (u Uintptr) Cmp(val Uintptr) int {
+	return cmp.Compare(u, val)
+}
+
+// Float32 is a float32 which implements Primitive and Comparable
+type Float32 float32
+
+// Cast returns the value as a float32
+func (f Float32) Cast() float32 {
+	return float32(f)
+}
+
+// Cmp compares the value with val, NaN is less than every other value
+func (f Float32) Cmp(val Float32) int {
+	return cmp.Compare(f, val)
+}
+
+// Float64 is a float64 which implements Primitive and Comparable
+type Float64 float64
+
+// Cast returns the value as a float64
+func (f Float64) Cast() float64 {
+	return float64(f)
+}
+
+// Cmp compares the value with val, NaN is less than every other value
+func (f Float64) Cmp(val Float64) int {
+	return cmp.Compare(f, val)
+}
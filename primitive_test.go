@@ -0,0 +1,62 @@
+package alloc
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testPrimitiveOrder stores the values as keys in a BTree and SkipList, and
+// checks they come back out in the same order as the go values they wrap
+func testPrimitiveOrder[K interface {
+	Comparable[K]
+	Primitive[T]
+}, T cmp.Ordered](t *testing.T, vals ...K) {
+	t.Helper()
+	arena := NewExpandingAllocator(8)
+	tree := Must(NewBTree[K, T](&arena))
+	list := Must(NewSkipList[K, T](&arena))
+
+	for _, v := range vals {
+		assert.NoError(t, tree.Set(v, v.Cast()))
+		assert.NoError(t, list.Insert(v, v.Cast()))
+	}
+
+	var want []T
+	for _, v := range vals {
+		want = append(want, v.Cast())
+	}
+	slices.Sort(want)
+	var fromTree, fromList []T
+	for k, v := range tree.All() {
+		assert.Equal(t, v, k.Cast())
+		fromTree = append(fromTree, v)
+	}
+
+	for k, v := range list.All() {
+		assert.Equal(t, v, k.Cast())
+		fromList = append(fromList, v)
+	}
+
+	assert.Equal(t, want, fromTree)
+	assert.Equal(t, want, fromList)
+}
+
+func TestPrimitiveOrder(t *testing.T) {
+	testPrimitiveOrder[Int, int](t, 3, -1, math.MaxInt, math.MinInt)
+	testPrimitiveOrder[Int8, int8](t, 3, -1, math.MaxInt8, math.MinInt8)
+	testPrimitiveOrder[Int16, int16](t, 3, -1, math.MaxInt16, math.MinInt16)
+	testPrimitiveOrder[Int32, int32](t, 3, -1, math.MaxInt32, math.MinInt32)
+	testPrimitiveOrder[Int64, int64](t, 3, -1, math.MaxInt64, math.MinInt64)
+	testPrimitiveOrder[Uint, uint](t, 3, 0, math.MaxUint)
+	testPrimitiveOrder[Uint8, uint8](t, 3, 0, math.MaxUint8)
+	testPrimitiveOrder[Uint16, uint16](t, 3, 0, math.MaxUint16)
+	testPrimitiveOrder[Uint32, uint32](t, 3, 0, math.MaxUint32)
+	testPrimitiveOrder[Uint64, uint64](t, 3, 0, math.MaxUint64)
+	testPrimitiveOrder[Uintptr, uintptr](t, 3, 0, 1<<20)
+	testPrimitiveOrder[Float32, float32](t, 0.5, -1.5, Float32(math.Inf(1)), math.MaxFloat32)
+	testPrimitiveOrder[Float64, float64](t, 0.5, -1.5, Float64(math.Inf(-1)), math.MaxFloat64)
+}
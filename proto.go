@@ -0,0 +1,545 @@
+package alloc
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"unsafe"
+)
+
+var ErrInvalidProto = errors.New("invalid protobuf")
+
+// protoMaxDepth limits how deeply messages can be nested
+const protoMaxDepth = 100
+
+// ProtoKind is the protobuf type of a field. Each kind is decoded into a
+// specific go type within the struct
+//
+//	ProtoInt32, ProtoSint32, ProtoSfixed32, ProtoEnum  int32
+//	ProtoInt64, ProtoSint64, ProtoSfixed64             int64
+//	ProtoUint32, ProtoFixed32                          uint32
+//	ProtoUint64, ProtoFixed64                          uint64
+//	ProtoBool                                          bool
+//	ProtoFloat                                         float32
+//	ProtoDouble                                        float64
+//	ProtoString                                        String
+//	ProtoBytes                                         Array[byte]
+//	ProtoMessage                                       Ptr[M]
+//
+// Repeated fields are decoded into an Array of the type above, except for
+// messages which are stored directly in the array as Array[M]
+type ProtoKind uint8
+
+const (
+	ProtoInt32 ProtoKind = iota + 1
+	ProtoInt64
+	ProtoUint32
+	ProtoUint64
+	ProtoSint32
+	ProtoSint64
+	ProtoBool
+	ProtoEnum
+	ProtoFixed32
+	ProtoFixed64
+	ProtoSfixed32
+	ProtoSfixed64
+	ProtoFloat
+	ProtoDouble
+	ProtoString
+	ProtoBytes
+	ProtoMessage
+)
+
+// protobuf wire types
+const (
+	wireVarint     = 0
+	wireFixed64    = 1
+	wireLen        = 2
+	wireStartGroup = 3
+	wireEndGroup   = 4
+	wireFixed32    = 5
+)
+
+// wireType returns the wire type used to encode a single value of the kind
+func (k ProtoKind) wireType() int {
+	switch k {
+	case ProtoFixed64, ProtoSfixed64, ProtoDouble:
+		return wireFixed64
+	case ProtoFixed32, ProtoSfixed32, ProtoFloat:
+		return wireFixed32
+	case ProtoString, ProtoBytes, ProtoMessage:
+		return wireLen
+	default:
+		return wireVarint
+	}
+}
+
+// layout returns the size and alignment of the go type the kind is decoded
+// into
+func (k ProtoKind) layout() (uintptr, uintptr) {
+	switch k {
+	case ProtoBool:
+		return 1, 1
+	case ProtoInt32, ProtoSint32, ProtoSfixed32, ProtoEnum, ProtoUint32, ProtoFixed32, ProtoFloat:
+		return 4, 4
+	case ProtoString, ProtoBytes:
+		return unsafe.Sizeof(String{}), unsafe.Alignof(String{})
+	case ProtoMessage:
+		return unsafe.Sizeof(Ptr[byte]{}), unsafe.Alignof(Ptr[byte]{})
+	default:
+		return 8, 8
+	}
+}
+
+// ProtoField describes a single field of a message, and where it is stored
+// within the go struct
+type ProtoField struct {
+	// Number is the field number from the .proto file
+	Number uint32
+	Kind   ProtoKind
+	// Repeated fields are stored in an Array
+	Repeated bool
+	// Offset is the location of the field in the struct, use unsafe.Offsetof
+	Offset uintptr
+	// Message describes the nested message when Kind is ProtoMessage
+	Message *ProtoSchema
+}
+
+// ProtoSchema is the schema used to decode a message into a go struct.
+// Fields not in the schema are skipped while decoding.
+type ProtoSchema struct {
+	Size   uintptr
+	Align  uintptr
+	Fields []ProtoField
+}
+
+// NewProtoSchema creates the schema for decoding into T. It panics if a
+// field does not fit within T, or a message field is missing its Message
+//
+//	type Person struct {
+//	  Name String
+//	  Age  int32
+//	}
+//
+//	var personSchema = alloc.NewProtoSchema[Person](
+//	  alloc.ProtoField{Number: 1, Kind: alloc.ProtoString, Offset: unsafe.Offsetof(Person{}.Name)},
+//	  alloc.ProtoField{Number: 2, Kind: alloc.ProtoInt32, Offset: unsafe.Offsetof(Person{}.Age)},
+//	)
+func NewProtoSchema[T any](fields ...ProtoField) *ProtoSchema {
+	m := &ProtoSchema{
+		Size:   unsafe.Sizeof(*new(T)),
+		Align:  unsafe.Alignof(*new(T)),
+		Fields: fields,
+	}
+
+	for _, f := range fields {
+		size, _ := f.Kind.layout()
+		if f.Repeated {
+			size = unsafe.Sizeof(Array[byte]{})
+		}
+
+		if f.Offset+size > m.Size {
+			panic(fmt.Sprintf("field %d does not fit within the message", f.Number))
+		}
+
+		if f.Kind == ProtoMessage && f.Message == nil {
+			panic(fmt.Sprintf("field %d is missing its message", f.Number))
+		}
+	}
+
+	return m
+}
+
+// field returns the field with the number, or nil if it isn't in the schema
+func (m *ProtoSchema) field(number uint32) *ProtoField {
+	for x := range m.Fields {
+		if m.Fields[x].Number == number {
+			return &m.Fields[x]
+		}
+	}
+
+	return nil
+}
+
+// DecodeProto decodes the protobuf wire format in b into a new T within
+// the allocator, using msg to find where each field belongs. Every string,
+// repeated field and nested message is stored in the allocator, so the only
+// heap allocations are the ones made by the allocator itself.
+func DecodeProto[T any](a Allocator, msg *ProtoSchema, b []byte) (Ptr[T], error) {
+	if msg.Size != unsafe.Sizeof(*new(T)) {
+		panic("message schema does not match the size of the type")
+	}
+
+	offset, err := a.Alloc(msg.Size, msg.Align)
+	if err != nil {
+		return Ptr[T]{}, err
+	}
+
+	d := protoDecoder{a: a}
+	if err := d.message(msg, b, offset, 0); err != nil {
+		return Ptr[T]{}, err
+	}
+
+	return Ptr[T]{offset: offset, alloc: a}, nil
+}
+
+type protoDecoder struct {
+	a Allocator
+}
+
+// protoRecord is a single field read from the wire
+type protoRecord struct {
+	number uint32
+	wire   int
+	// value holds varint and fixed values
+	value uint64
+	// payload holds length delimited values
+	payload []byte
+}
+
+// next reads the record at the start of b, and returns the number of
+// bytes used. Groups are skipped over as a whole, leaving a record with no
+// value
+func (d *protoDecoder) next(b []byte) (protoRecord, int, error) {
+	r, n, err := protoTag(b)
+	if err != nil {
+		return protoRecord{}, 0, err
+	}
+
+	var m int
+	switch r.wire {
+	case wireStartGroup:
+		m, err = protoSkipGroup(b[n:], r.number, 1)
+	case wireEndGroup:
+		err = fmt.Errorf("%w: end of group %d which was never started", ErrInvalidProto, r.number)
+	default:
+		m, err = r.read(b[n:])
+	}
+
+	return r, n + m, err
+}
+
+// protoTag reads the tag at the start of b, returning the record it starts
+// and the number of bytes used
+func protoTag(b []byte) (protoRecord, int, error) {
+	tag, n := protoVarint(b)
+	if n == 0 {
+		return protoRecord{}, 0, fmt.Errorf("%w: bad tag", ErrInvalidProto)
+	}
+
+	if tag>>3 == 0 || tag>>3 > math.MaxInt32 {
+		return protoRecord{}, 0, fmt.Errorf("%w: bad field number %d", ErrInvalidProto, tag>>3)
+	}
+
+	return protoRecord{number: uint32(tag >> 3), wire: int(tag & 7)}, n, nil
+}
+
+// protoSkipGroup skips the records of the group with the number, up to and
+// including the record which ends it, and returns the bytes used. Groups are
+// deprecated, so their contents are never decoded
+func protoSkipGroup(b []byte, number uint32, depth int) (int, error) {
+	if depth > protoMaxDepth {
+		return 0, fmt.Errorf("%w: nested too deeply", ErrInvalidProto)
+	}
+
+	for pos := 0; pos < len(b); {
+		r, n, err := protoTag(b[pos:])
+		if err != nil {
+			return 0, err
+		}
+		pos += n
+
+		var m int
+		switch r.wire {
+		case wireStartGroup:
+			m, err = protoSkipGroup(b[pos:], r.number, depth+1)
+		case wireEndGroup:
+			if r.number != number {
+				return 0, fmt.Errorf("%w: group %d ended by field %d", ErrInvalidProto, number, r.number)
+			}
+
+			return pos, nil
+		default:
+			m, err = r.read(b[pos:])
+		}
+
+		if err != nil {
+			return 0, err
+		}
+		pos += m
+	}
+
+	return 0, fmt.Errorf("%w: group %d is truncated", ErrInvalidProto, number)
+}
+
+// read reads the value of the record from b, returning the bytes used
+func (r *protoRecord) read(b []byte) (int, error) {
+	switch r.wire {
+	case wireVarint:
+		v, n := protoVarint(b)
+		if n == 0 {
+			return 0, fmt.Errorf("%w: bad varint in field %d", ErrInvalidProto, r.number)
+		}
+
+		r.value = v
+		return n, nil
+	case wireFixed64:
+		if len(b) < 8 {
+			return 0, fmt.Errorf("%w: field %d is truncated", ErrInvalidProto, r.number)
+		}
+
+		r.value = binary.LittleEndian.Uint64(b)
+		return 8, nil
+	case wireFixed32:
+		if len(b) < 4 {
+			return 0, fmt.Errorf("%w: field %d is truncated", ErrInvalidProto, r.number)
+		}
+
+		r.value = uint64(binary.LittleEndian.Uint32(b))
+		return 4, nil
+	case wireLen:
+		l, n := protoVarint(b)
+		if n == 0 || l > uint64(len(b)-n) {
+			return 0, fmt.Errorf("%w: field %d is truncated", ErrInvalidProto, r.number)
+		}
+
+		r.payload = b[n : n+int(l)]
+		return n + int(l), nil
+	default:
+		return 0, fmt.Errorf("%w: unsupported wire type %d in field %d", ErrInvalidProto, r.wire, r.number)
+	}
+}
+
+// message decodes b into the struct at offset. Singular fields are written
+// as they are found, while repeated fields are only counted, keeping the
+// count in the length of their array. Once every array has been allocated
+// with the correct size, a second pass fills them in.
+func (d *protoDecoder) message(msg *ProtoSchema, b []byte, offset uintptr, depth int) error {
+	if depth > protoMaxDepth {
+		return fmt.Errorf("%w: nested too deeply", ErrInvalidProto)
+	}
+
+	// the allocator could hand us memory which was used before a reset
+	clear(unsafe.Slice((*byte)(d.a.Offset(offset)), msg.Size))
+
+	repeated := 0
+	for pos := 0; pos < len(b); {
+		r, n, err := d.next(b[pos:])
+		if err != nil {
+			return err
+		}
+		pos += n
+
+		f := msg.field(r.number)
+		if f == nil {
+			continue
+		}
+
+		if f.Repeated {
+			count, err := d.each(f, r, nil)
+			if err != nil {
+				return err
+			}
+
+			(*Array[byte])(d.a.Offset(offset + f.Offset)).len += count
+			repeated += count
+			continue
+		}
+
+		if r.wire != f.Kind.wireType() {
+			return fmt.Errorf("%w: wrong wire type %d for field %d", ErrInvalidProto, r.wire, r.number)
+		}
+
+		if err := d.value(f, r, offset+f.Offset, depth); err != nil {
+			return err
+		}
+	}
+
+	for x := range msg.Fields {
+		f := &msg.Fields[x]
+		if !f.Repeated {
+			continue
+		}
+
+		size, align := f.elemLayout()
+		count := (*Array[byte])(d.a.Offset(offset + f.Offset)).len
+		data, err := d.a.Alloc(size*uintptr(count), align)
+		if err != nil {
+			return err
+		}
+
+		// the length goes back up as the array is filled in
+		*(*Array[byte])(d.a.Offset(offset + f.Offset)) = Array[byte]{
+			data: Ptr[byte]{offset: data, alloc: d.a},
+		}
+	}
+
+	if repeated == 0 {
+		return nil
+	}
+
+	for pos := 0; pos < len(b); {
+		r, n, err := d.next(b[pos:])
+		if err != nil {
+			return err
+		}
+		pos += n
+
+		f := msg.field(r.number)
+		if f == nil || !f.Repeated {
+			continue
+		}
+
+		_, err = d.each(f, r, func(v protoRecord) error {
+			return d.append(f, v, offset+f.Offset, depth)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// elemLayout returns the size and alignment of a single element in the
+// array of a repeated field
+func (f *ProtoField) elemLayout() (uintptr, uintptr) {
+	if f.Kind == ProtoMessage {
+		return f.Message.Size, f.Message.Align
+	}
+
+	return f.Kind.layout()
+}
+
+// append decodes r into the next element of the array at offset, which
+// already has room for it
+func (d *protoDecoder) append(f *ProtoField, r protoRecord, offset uintptr, depth int) error {
+	size, _ := f.elemLayout()
+
+	// decoding can allocate and move the array, so it is only touched before
+	arr := (*Array[byte])(d.a.Offset(offset))
+	elem := arr.data.offset + uintptr(arr.len)*size
+	arr.len++
+
+	if f.Kind == ProtoMessage {
+		return d.message(f.Message, r.payload, elem, depth+1)
+	}
+
+	return d.value(f, r, elem, depth)
+}
+
+// each calls fn with every value of the repeated field f held in r,
+// unpacking packed scalars, and returns how many there were. fn can be nil
+// to only count the values
+func (d *protoDecoder) each(f *ProtoField, r protoRecord, fn func(protoRecord) error) (int, error) {
+	wire := f.Kind.wireType()
+
+	// scalars can be packed into a single length delimited record
+	if r.wire == wireLen && wire != wireLen {
+		count := 0
+		for packed := r.payload; len(packed) > 0; {
+			v := protoRecord{number: r.number, wire: wire}
+			m, err := v.read(packed)
+			if err != nil {
+				return 0, err
+			}
+			packed = packed[m:]
+
+			count++
+			if fn != nil {
+				if err := fn(v); err != nil {
+					return 0, err
+				}
+			}
+		}
+
+		return count, nil
+	}
+
+	if r.wire != wire {
+		return 0, fmt.Errorf("%w: wrong wire type %d for field %d", ErrInvalidProto, r.wire, r.number)
+	}
+
+	if fn != nil {
+		if err := fn(r); err != nil {
+			return 0, err
+		}
+	}
+
+	return 1, nil
+}
+
+// value writes a single value of the field to offset
+func (d *protoDecoder) value(f *ProtoField, r protoRecord, offset uintptr, depth int) error {
+	switch f.Kind {
+	case ProtoString, ProtoBytes:
+		data, err := d.a.Alloc(uintptr(len(r.payload)), 1)
+		if err != nil {
+			return err
+		}
+
+		if len(r.payload) > 0 {
+			copy(unsafe.Slice((*byte)(d.a.Offset(data)), len(r.payload)), r.payload)
+		}
+
+		*(*Array[byte])(d.a.Offset(offset)) = Array[byte]{
+			data: Ptr[byte]{offset: data, alloc: d.a},
+			len:  len(r.payload),
+		}
+	case ProtoMessage:
+		data, err := d.a.Alloc(f.Message.Size, f.Message.Align)
+		if err != nil {
+			return err
+		}
+
+		if err := d.message(f.Message, r.payload, data, depth+1); err != nil {
+			return err
+		}
+
+		*(*Ptr[byte])(d.a.Offset(offset)) = Ptr[byte]{offset: data, alloc: d.a}
+	default:
+		storeProtoScalar(d.a.Offset(offset), f.Kind, r.value)
+	}
+
+	return nil
+}
+
+// storeProtoScalar converts the raw value read from the wire into the go
+// type for the kind, and stores it at ptr
+func storeProtoScalar(ptr unsafe.Pointer, kind ProtoKind, v uint64) {
+	switch kind {
+	case ProtoInt32, ProtoEnum, ProtoSfixed32:
+		*(*int32)(ptr) = int32(v)
+	case ProtoInt64, ProtoSfixed64:
+		*(*int64)(ptr) = int64(v)
+	case ProtoUint32, ProtoFixed32:
+		*(*uint32)(ptr) = uint32(v)
+	case ProtoUint64, ProtoFixed64:
+		*(*uint64)(ptr) = v
+	case ProtoSint32:
+		*(*int32)(ptr) = int32(uint32(v)>>1) ^ -int32(v&1)
+	case ProtoSint64:
+		*(*int64)(ptr) = int64(v>>1) ^ -int64(v&1)
+	case ProtoBool:
+		*(*bool)(ptr) = v != 0
+	case ProtoFloat:
+		*(*float32)(ptr) = math.Float32frombits(uint32(v))
+	case ProtoDouble:
+		*(*float64)(ptr) = math.Float64frombits(v)
+	}
+}
+
+// protoVarint reads a varint from b, returning the value and the number
+// of bytes read. 0 bytes are returned if the varint is invalid
+func protoVarint(b []byte) (uint64, int) {
+	var v uint64
+	for x := 0; x < len(b) && x < binary.MaxVarintLen64; x++ {
+		v |= uint64(b[x]&0x7f) << (7 * x)
+		if b[x] < 0x80 {
+			return v, x + 1
+		}
+	}
+
+	return 0, 0
+}
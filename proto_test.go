@@ -0,0 +1,166 @@
+package alloc
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testPhone struct {
+	Number String
+	Kind   int32
+}
+
+type testAddress struct {
+	City String
+}
+
+type testPerson struct {
+	Name    String
+	ID      int32
+	Delta   int64
+	Active  bool
+	Ratio   float64
+	Weight  float32
+	Avatar  Array[byte]
+	Address Ptr[testAddress]
+	Phones  Array[testPhone]
+	Scores  Array[uint64]
+	Tags    Array[String]
+}
+
+var (
+	testPhoneSchema = NewProtoSchema[testPhone](
+		ProtoField{Number: 1, Kind: ProtoString, Offset: unsafe.Offsetof(testPhone{}.Number)},
+		ProtoField{Number: 2, Kind: ProtoEnum, Offset: unsafe.Offsetof(testPhone{}.Kind)},
+	)
+	testAddressSchema = NewProtoSchema[testAddress](
+		ProtoField{Number: 1, Kind: ProtoString, Offset: unsafe.Offsetof(testAddress{}.City)},
+	)
+	testPersonSchema = NewProtoSchema[testPerson](
+		ProtoField{Number: 1, Kind: ProtoString, Offset: unsafe.Offsetof(testPerson{}.Name)},
+		ProtoField{Number: 2, Kind: ProtoInt32, Offset: unsafe.Offsetof(testPerson{}.ID)},
+		ProtoField{Number: 3, Kind: ProtoSint64, Offset: unsafe.Offsetof(testPerson{}.Delta)},
+		ProtoField{Number: 4, Kind: ProtoBool, Offset: unsafe.Offsetof(testPerson{}.Active)},
+		ProtoField{Number: 5, Kind: ProtoDouble, Offset: unsafe.Offsetof(testPerson{}.Ratio)},
+		ProtoField{Number: 6, Kind: ProtoFloat, Offset: unsafe.Offsetof(testPerson{}.Weight)},
+		ProtoField{Number: 7, Kind: ProtoBytes, Offset: unsafe.Offsetof(testPerson{}.Avatar)},
+		ProtoField{Number: 8, Kind: ProtoMessage, Offset: unsafe.Offsetof(testPerson{}.Address), Message: testAddressSchema},
+		ProtoField{Number: 9, Kind: ProtoMessage, Repeated: true, Offset: unsafe.Offsetof(testPerson{}.Phones), Message: testPhoneSchema},
+		ProtoField{Number: 10, Kind: ProtoUint64, Repeated: true, Offset: unsafe.Offsetof(testPerson{}.Scores)},
+		ProtoField{Number: 11, Kind: ProtoString, Repeated: true, Offset: unsafe.Offsetof(testPerson{}.Tags)},
+	)
+)
+
+// protoBuilder writes the protobuf wire format for tests
+type protoBuilder []byte
+
+func (b protoBuilder) tag(number, wire int) protoBuilder {
+	return binary.AppendUvarint(b, uint64(number<<3|wire))
+}
+
+func (b protoBuilder) varint(number int, v uint64) protoBuilder {
+	return binary.AppendUvarint(b.tag(number, wireVarint), v)
+}
+
+func (b protoBuilder) bytes(number int, v []byte) protoBuilder {
+	b = binary.AppendUvarint(b.tag(number, wireLen), uint64(len(v)))
+	return append(b, v...)
+}
+
+func TestDecodeProto(t *testing.T) {
+	phone := func(number string, kind uint64) []byte {
+		return protoBuilder{}.bytes(1, []byte(number)).varint(2, kind)
+	}
+
+	var packed []byte
+	for _, v := range []uint64{1, 300, math.MaxUint64} {
+		packed = binary.AppendUvarint(packed, v)
+	}
+
+	msg := protoBuilder{}.
+		bytes(1, []byte("alice")).
+		varint(2, 42).
+		varint(3, 3). // zigzag -2
+		varint(4, 1).
+		tag(5, wireFixed64)
+	msg = binary.LittleEndian.AppendUint64(msg, math.Float64bits(0.25))
+	msg = msg.tag(6, wireFixed32)
+	msg = binary.LittleEndian.AppendUint32(msg, math.Float32bits(1.5))
+	msg = msg.
+		bytes(7, []byte{0xde, 0xad}).
+		bytes(8, protoBuilder{}.bytes(1, []byte("paris"))).
+		bytes(9, phone("555-1234", 1)).
+		varint(99, 7).           // unknown fields are skipped
+		tag(98, wireStartGroup). // and so are groups, nested or not
+		varint(1, 1).
+		tag(97, wireStartGroup).
+		bytes(2, []byte("x")).
+		tag(97, wireEndGroup).
+		tag(98, wireEndGroup).
+		bytes(9, phone("555-9876", 2)).
+		bytes(10, packed).
+		varint(10, 7). // unpacked values can follow packed ones
+		bytes(11, []byte("a")).
+		bytes(11, []byte(""))
+
+	arena := NewExpandingAllocator(8)
+	p, err := DecodeProto[testPerson](&arena, testPersonSchema, msg)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	person := p.Deref()
+	assert.Equal(t, "alice", person.Name.String())
+	assert.Equal(t, int32(42), person.ID)
+	assert.Equal(t, int64(-2), person.Delta)
+	assert.Equal(t, true, person.Active)
+	assert.Equal(t, 0.25, person.Ratio)
+	assert.Equal(t, float32(1.5), person.Weight)
+	assert.Equal(t, []byte{0xde, 0xad}, person.Avatar.Slice())
+	assert.Equal(t, "paris", person.Address.Deref().City.String())
+
+	if assert.Equal(t, 2, person.Phones.Length()) {
+		assert.Equal(t, "555-1234", person.Phones.Slice()[0].Number.String())
+		assert.Equal(t, int32(1), person.Phones.Slice()[0].Kind)
+		assert.Equal(t, "555-9876", person.Phones.Slice()[1].Number.String())
+		assert.Equal(t, int32(2), person.Phones.Slice()[1].Kind)
+	}
+
+	assert.Equal(t, []uint64{1, 300, math.MaxUint64, 7}, person.Scores.Slice())
+
+	if assert.Equal(t, 2, person.Tags.Length()) {
+		assert.Equal(t, "a", person.Tags.Slice()[0].String())
+		assert.Equal(t, "", person.Tags.Slice()[1].String())
+	}
+
+	// fields which were not set are left empty
+	empty, err := DecodeProto[testPerson](&arena, testPersonSchema, nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "", empty.Deref().Name.String())
+		assert.True(t, empty.Deref().Address.IsNull())
+		assert.Equal(t, 0, empty.Deref().Phones.Length())
+	}
+}
+
+func TestDecodeProtoInvalid(t *testing.T) {
+	for _, msg := range [][]byte{
+		{0x0a},             // missing length
+		{0x0a, 0x05, 'a'},  // truncated string
+		{0x10},             // missing varint
+		{0x10, 0x80},       // unterminated varint
+		{0x0b},             // unterminated group
+		{0x0c},             // end of a group which never started
+		{0x0b, 0x14},       // group 1 ended by field 2
+		{0x12, 0x00},       // length delimited int32
+		{0x00, 0x00},       // field number 0
+		{0x42, 0x02, 0x0a}, // truncated nested message
+	} {
+		arena := NewExpandingAllocator(8)
+		_, err := DecodeProto[testPerson](&arena, testPersonSchema, msg)
+		assert.ErrorIs(t, err, ErrInvalidProto, msg)
+	}
+}
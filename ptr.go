@@ -1,6 +1,12 @@
 package alloc
 
-import "unsafe"
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+)
+
+var ErrInvalidReinterpret = errors.New("invalid pointer reinterpret")
 
 // Ptr returns a pointer to the underlying value. This pointer
 // tracks the allocator used to provision it. When using an allocator
@@ -13,10 +19,20 @@ type Ptr[T any] struct {
 	alloc  Allocator
 }
 
-// Defer return the underlying type as a pointer
-func (p Ptr[T]) Deref() *T {
-	ptr := p.alloc.Offset(p.offset)
-	return (*T)(ptr)
+// TryDeref returns the underlying type as a pointer like Deref, but checks
+// the value is within the memory the allocator has handed out first. A null
+// Ptr or one past the allocated memory returns ErrOutOfRange
+func (p Ptr[T]) TryDeref() (*T, error) {
+	if p.IsNull() {
+		return nil, fmt.Errorf("%w: null pointer", ErrOutOfRange)
+	}
+
+	ptr, err := p.alloc.OffsetChecked(p.offset, unsafe.Sizeof(*new(T)))
+	if err != nil {
+		return nil, err
+	}
+
+	return (*T)(ptr), nil
 }
 
 // Set is just a shorthand to deref the value and set
@@ -41,3 +57,47 @@ func (p Ptr[T]) IsNull() bool {
 func (p *Ptr[T]) Null() {
 	p.alloc = nil
 }
+
+// Field returns a Ptr to the field of type F at offset within the value p
+// points to. Use unsafe.Offsetof to find the offset. The Ptr stays valid
+// when the allocator moves its memory, unlike a go pointer to the field
+//
+//	count := Field[int](p, unsafe.Offsetof(Thing{}.count))
+func Field[F, T any](p Ptr[T], offset uintptr) Ptr[F] {
+	if offset+unsafe.Sizeof(*new(F)) > unsafe.Sizeof(*new(T)) || offset%unsafe.Alignof(*new(F)) != 0 {
+		panic(fmt.Sprintf("field at offset %d does not fit in %T", offset, *new(T)))
+	}
+
+	return Ptr[F]{offset: p.offset + offset, alloc: p.alloc}
+}
+
+// Elem returns a Ptr to element i of the array
+func Elem[T any](arr Array[T], i int) Ptr[T] {
+	if i < 0 || i >= arr.len {
+		panic(fmt.Sprintf("index %d out of range for array of length %d", i, arr.len))
+	}
+
+	return arr.ptr(i)
+}
+
+// Add returns a Ptr n elements of T after p, or before it when n is
+// negative. Nothing is checked, so only use it within an Array
+func Add[T any](p Ptr[T], n int) Ptr[T] {
+	p.offset = uintptr(int(p.offset) + n*int(unsafe.Sizeof(*new(T))))
+	return p
+}
+
+// Reinterpret returns p as a Ptr to U. U must be no larger than T, and p
+// must be aligned for U, otherwise ErrInvalidReinterpret is returned
+func Reinterpret[U, T any](p Ptr[T]) (Ptr[U], error) {
+	size, align := unsafe.Sizeof(*new(U)), unsafe.Alignof(*new(U))
+	if size > unsafe.Sizeof(*new(T)) {
+		return Ptr[U]{}, fmt.Errorf("%w: %T is larger than %T", ErrInvalidReinterpret, *new(U), *new(T))
+	}
+
+	if p.offset%align != 0 {
+		return Ptr[U]{}, fmt.Errorf("%w: offset %d is not aligned to %d for %T", ErrInvalidReinterpret, p.offset, align, *new(U))
+	}
+
+	return Ptr[U]{offset: p.offset, alloc: p.alloc}, nil
+}
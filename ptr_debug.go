@@ -0,0 +1,15 @@
+//go:build allocdebug
+
+package alloc
+
+// Deref return the underlying type as a pointer. This is the allocdebug
+// build, which checks the value is within the allocated memory and panics
+// if it isn't
+func (p Ptr[T]) Deref() *T {
+	ptr, err := p.TryDeref()
+	if err != nil {
+		panic(err)
+	}
+
+	return ptr
+}
@@ -0,0 +1,18 @@
+//go:build allocdebug
+
+package alloc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDerefChecked(t *testing.T) {
+	arena := NewPageAllocator()
+	i := Must(New[uint64](&arena))
+	assert.NotPanics(t, func() { i.Deref() })
+
+	arena.Reset()
+	assert.Panics(t, func() { i.Deref() })
+}
@@ -0,0 +1,10 @@
+//go:build !allocdebug
+
+package alloc
+
+// Deref return the underlying type as a pointer. Build with the allocdebug
+// tag to check every Deref is within the allocated memory
+func (p Ptr[T]) Deref() *T {
+	ptr := p.alloc.Offset(p.offset)
+	return (*T)(ptr)
+}
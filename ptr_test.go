@@ -0,0 +1,52 @@
+package alloc
+
+import (
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type ptrPoint struct {
+	X, Y int32
+	Tag  uint64
+}
+
+func TestPtrHelpers(t *testing.T) {
+	arena := NewExpandingAllocator(8)
+	p := Must(New[ptrPoint](&arena))
+	p.Set(ptrPoint{X: 1, Y: 2, Tag: 3})
+
+	y := Field[int32](p, unsafe.Offsetof(ptrPoint{}.Y))
+	tag := Field[uint64](p, unsafe.Offsetof(ptrPoint{}.Tag))
+
+	// field pointers survive the allocator moving its memory
+	Must(NewArray[byte](&arena, 1024))
+	y.Set(20)
+	assert.Equal(t, int32(20), p.Deref().Y)
+	assert.Equal(t, uint64(3), *tag.Deref())
+
+	assert.Panics(t, func() { Field[uint64](p, 12) })
+	assert.Panics(t, func() { Field[int32](p, 2) })
+
+	arr := *Must(NewArray[int](&arena, 4)).Deref()
+	copy(arr.Slice(), []int{10, 20, 30, 40})
+
+	e := Elem(arr, 1)
+	assert.Equal(t, 20, *e.Deref())
+	assert.Equal(t, 40, *Add(e, 2).Deref())
+	assert.Equal(t, 10, *Add(e, -1).Deref())
+	assert.Panics(t, func() { Elem(arr, 4) })
+
+	// view the point's X and Y as an array
+	xy := Must(Reinterpret[[2]int32](p))
+	assert.Equal(t, [2]int32{1, 20}, *xy.Deref())
+
+	_, err := Reinterpret[[4]uint64](p)
+	assert.ErrorIs(t, err, ErrInvalidReinterpret)
+
+	// Y and the first half of Tag fit in a uint64, but aren't aligned for one
+	yTag := Field[[2]int32](p, unsafe.Offsetof(ptrPoint{}.Y))
+	_, err = Reinterpret[uint64](yTag)
+	assert.ErrorIs(t, err, ErrInvalidReinterpret)
+}
@@ -0,0 +1,363 @@
+package alloc
+
+import (
+	"cmp"
+	"iter"
+	"slices"
+	"strings"
+)
+
+// radixNode is a node in the tree. The label is the part of the key on the
+// edge from the parent, and children are kept sorted by the first byte of
+// their label
+type radixNode[V any] struct {
+	label    String
+	children Array[Ptr[radixNode[V]]]
+	len      int
+	leaf     bool
+	val      V
+}
+
+// RadixTree is a compressed prefix tree with String keys, stored in the
+// allocator. Keys sharing a prefix share the nodes for it, which makes
+// prefix lookups like routing tables cheap.
+//
+// Edge labels are views into the keys passed to Insert, so no bytes are
+// copied and the keys must not change while they are in the tree. Since a
+// label sits at the same position in its key as the node does in the tree,
+// the full key can always be recovered from a label without allocating.
+//
+// The RadixTree is a header pointing to the root node.
+type RadixTree[V any] struct {
+	root  Ptr[radixNode[V]]
+	len   int
+	alloc Allocator
+}
+
+// NewRadixTree creates an empty tree in the allocator
+func NewRadixTree[V any](a Allocator) (RadixTree[V], error) {
+	t := RadixTree[V]{alloc: a}
+
+	root, err := t.newNode(String{})
+	if err != nil {
+		return RadixTree[V]{}, err
+	}
+
+	t.root = root
+	return t, nil
+}
+
+// newNode creates a node with no children or value
+func (t *RadixTree[V]) newNode(label String) (Ptr[radixNode[V]], error) {
+	n, err := New[radixNode[V]](t.alloc)
+	if err != nil {
+		return Ptr[radixNode[V]]{}, err
+	}
+
+	n.Set(radixNode[V]{label: label})
+	return n, nil
+}
+
+// child returns the index of the child whose label starts with b. If there
+// isn't one, the index it should be inserted at is returned with false
+func (n *radixNode[V]) child(b byte) (int, bool) {
+	return slices.BinarySearchFunc(n.children.Slice()[:n.len], b, func(c Ptr[radixNode[V]], b byte) int {
+		return cmp.Compare(c.Deref().label.Cast()[0], b)
+	})
+}
+
+// addChild inserts child into n at index x, growing the children when they
+// are full
+func (t *RadixTree[V]) addChild(n Ptr[radixNode[V]], x int, child Ptr[radixNode[V]]) error {
+	if node := n.Deref(); node.len == node.children.Length() {
+		children, err := NewArray[Ptr[radixNode[V]]](t.alloc, max(2, node.len*2))
+		if err != nil {
+			return err
+		}
+
+		// the allocation could have moved the node
+		node = n.Deref()
+		copy(children.Deref().Slice(), node.children.Slice())
+		node.children = *children.Deref()
+	}
+
+	node := n.Deref()
+	children := node.children.Slice()[:node.len+1]
+	copy(children[x+1:], children[x:])
+	children[x] = child
+	node.len++
+	return nil
+}
+
+// radixKey returns the full key for a node at depth with the given label,
+// by extending the label backwards over the bytes it was cut from
+func radixKey(label String, depth int) String {
+	if depth == 0 {
+		return label
+	}
+
+	arr := Array[byte](label)
+	arr.data.offset -= uintptr(depth)
+	arr.len += depth
+	return String(arr)
+}
+
+// Len returns the number of keys in the tree
+func (t *RadixTree[V]) Len() int {
+	return t.len
+}
+
+// Insert sets the value for key, replacing the value if the key is already
+// in the tree. Allocation errors are returned when nodes have to be added
+func (t *RadixTree[V]) Insert(key String, val V) error {
+	n, depth := t.root, 0
+
+	for {
+		rest := key.Sub(depth, key.Len())
+		if rest.Len() == 0 {
+			node := n.Deref()
+			if !node.leaf {
+				t.len++
+			}
+
+			node.leaf, node.val = true, val
+			return nil
+		}
+
+		x, found := n.Deref().child(rest.Cast()[0])
+		if !found {
+			leaf, err := t.newNode(rest)
+			if err != nil {
+				return err
+			}
+
+			if err := t.addChild(n, x, leaf); err != nil {
+				return err
+			}
+
+			node := leaf.Deref()
+			node.leaf, node.val = true, val
+			t.len++
+			return nil
+		}
+
+		child := n.Deref().children.Slice()[x]
+		label := child.Deref().label
+		common := commonPrefix(label.Cast(), rest.Cast())
+		if common < label.Len() {
+			// the key leaves the edge part way, so split it with a node
+			// holding the shared part
+			mid, err := t.newNode(label.Sub(0, common))
+			if err != nil {
+				return err
+			}
+
+			if err := t.addChild(mid, 0, child); err != nil {
+				return err
+			}
+
+			child.Deref().label = label.Sub(common, label.Len())
+			n.Deref().children.Slice()[x] = mid
+			child = mid
+		}
+
+		n, depth = child, depth+common
+	}
+}
+
+// commonPrefix returns the number of leading bytes a and b share
+func commonPrefix(a, b string) int {
+	n := min(len(a), len(b))
+	for x := range n {
+		if a[x] != b[x] {
+			return x
+		}
+	}
+
+	return n
+}
+
+// Get returns the value stored for key
+func (t *RadixTree[V]) Get(key string) (V, bool) {
+	n := t.root
+
+	for {
+		node := n.Deref()
+		if key == "" {
+			if !node.leaf {
+				return *new(V), false
+			}
+
+			return node.val, true
+		}
+
+		x, found := node.child(key[0])
+		if !found {
+			return *new(V), false
+		}
+
+		n = node.children.Slice()[x]
+		label := n.Deref().label.Cast()
+		if !strings.HasPrefix(key, label) {
+			return *new(V), false
+		}
+
+		key = key[len(label):]
+	}
+}
+
+// Has returns if the key is in the tree
+func (t *RadixTree[V]) Has(key string) bool {
+	_, ok := t.Get(key)
+	return ok
+}
+
+// Delete removes the key from the tree, returning the value it held and
+// true if it was there. Nodes left without a purpose are merged into their
+// child, but their memory is not reclaimed
+func (t *RadixTree[V]) Delete(key string) (V, bool) {
+	parent, n, px := Ptr[radixNode[V]]{}, t.root, 0
+
+	for key != "" {
+		node := n.Deref()
+		x, found := node.child(key[0])
+		if !found {
+			return *new(V), false
+		}
+
+		child := node.children.Slice()[x]
+		label := child.Deref().label.Cast()
+		if !strings.HasPrefix(key, label) {
+			return *new(V), false
+		}
+
+		parent, n, px = n, child, x
+		key = key[len(label):]
+	}
+
+	node := n.Deref()
+	if !node.leaf {
+		return *new(V), false
+	}
+
+	val := node.val
+	node.leaf, node.val = false, *new(V)
+	t.len--
+
+	if parent.IsNull() {
+		// the root holds the empty key, and always stays
+		return val, true
+	}
+
+	switch node.len {
+	case 0:
+		p := parent.Deref()
+		children := p.children.Slice()[:p.len]
+		copy(children[px:], children[px+1:])
+		p.len--
+
+		if parent != t.root && !p.leaf && p.len == 1 {
+			t.merge(parent)
+		}
+	case 1:
+		t.merge(n)
+	}
+
+	return val, true
+}
+
+// merge folds the only child of n into n, so the edge to n covers both
+// labels
+func (t *RadixTree[V]) merge(n Ptr[radixNode[V]]) {
+	node := n.Deref()
+	child := *node.children.Slice()[0].Deref()
+	child.label = radixKey(child.label, node.label.Len())
+	*node = child
+}
+
+// LongestPrefix returns the longest key in the tree which s starts with,
+// and its value
+func (t *RadixTree[V]) LongestPrefix(s string) (String, V, bool) {
+	var (
+		key   String
+		val   V
+		found bool
+	)
+
+	n, depth := t.root, 0
+	for {
+		node := n.Deref()
+		if node.leaf {
+			key, val, found = radixKey(node.label, depth-node.label.Len()), node.val, true
+		}
+
+		if depth == len(s) {
+			return key, val, found
+		}
+
+		x, ok := node.child(s[depth])
+		if !ok {
+			return key, val, found
+		}
+
+		n = node.children.Slice()[x]
+		label := n.Deref().label.Cast()
+		if !strings.HasPrefix(s[depth:], label) {
+			return key, val, found
+		}
+
+		depth += len(label)
+	}
+}
+
+// WalkPrefix returns an iterator over the keys starting with prefix and
+// their values, in sorted order. The tree must not be changed while
+// iterating
+func (t *RadixTree[V]) WalkPrefix(prefix string) iter.Seq2[String, V] {
+	return func(yield func(String, V) bool) {
+		n, depth := t.root, 0
+
+		for depth < len(prefix) {
+			node := n.Deref()
+			x, found := node.child(prefix[depth])
+			if !found {
+				return
+			}
+
+			n = node.children.Slice()[x]
+			label := n.Deref().label.Cast()
+			rest := prefix[depth:]
+			if !strings.HasPrefix(rest, label) && !strings.HasPrefix(label, rest) {
+				return
+			}
+
+			depth += len(label)
+		}
+
+		t.walk(n, depth-n.Deref().label.Len(), yield)
+	}
+}
+
+// All returns an iterator over every key in the tree and its value, in
+// sorted order
+func (t *RadixTree[V]) All() iter.Seq2[String, V] {
+	return t.WalkPrefix("")
+}
+
+// walk yields n and every node below it in sorted order, depth is where the
+// label of n starts within the key
+func (t *RadixTree[V]) walk(n Ptr[radixNode[V]], depth int, yield func(String, V) bool) bool {
+	node := n.Deref()
+	if node.leaf && !yield(radixKey(node.label, depth), node.val) {
+		return false
+	}
+
+	depth += node.label.Len()
+	for x := range node.len {
+		if !t.walk(node.children.Slice()[x], depth, yield) {
+			return false
+		}
+	}
+
+	return true
+}
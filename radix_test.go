@@ -0,0 +1,108 @@
+package alloc
+
+import (
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// radixKeys collects the keys from the iterator
+func radixKeys[V any](seq func(func(String, V) bool)) []string {
+	var keys []string
+	for k := range seq {
+		keys = append(keys, k.String())
+	}
+
+	return keys
+}
+
+func TestRadixTree(t *testing.T) {
+	arena := NewExpandingAllocator(8)
+	tree := Must(NewRadixTree[int](&arena))
+
+	// short keys over a small alphabet share lots of prefixes
+	rng := rand.New(rand.NewPCG(1, 2))
+	expected := map[string]int{}
+	for x := range 5000 {
+		b := make([]byte, rng.IntN(6))
+		for y := range b {
+			b[y] = "abc"[rng.IntN(3)]
+		}
+
+		k := string(b)
+		if rng.IntN(3) == 0 {
+			v, ok := tree.Delete(k)
+			ev, eok := expected[k]
+			assert.Equal(t, eok, ok)
+			assert.Equal(t, ev, v)
+			delete(expected, k)
+			continue
+		}
+
+		assert.NoError(t, tree.Insert(*Must(NewString(&arena, k)).Deref(), x))
+		expected[k] = x
+	}
+
+	assert.Equal(t, len(expected), tree.Len())
+
+	var want []string
+	for k, v := range expected {
+		want = append(want, k)
+
+		got, ok := tree.Get(k)
+		assert.True(t, ok)
+		assert.Equal(t, v, got)
+	}
+	slices.Sort(want)
+	assert.Equal(t, want, radixKeys(tree.All()))
+
+	for k := range expected {
+		_, ok := tree.Delete(k)
+		assert.True(t, ok)
+	}
+
+	assert.Equal(t, 0, tree.Len())
+	assert.Empty(t, radixKeys(tree.All()))
+}
+
+func TestRadixTreePrefix(t *testing.T) {
+	arena := NewExpandingAllocator(8)
+	tree := Must(NewRadixTree[string](&arena))
+	for _, route := range []string{"/", "/api", "/api/users", "/api/users/admin", "/app", "/static"} {
+		tree.Insert(*Must(NewString(&arena, route)).Deref(), strings.ToUpper(route))
+	}
+
+	_, ok := tree.Get("/ap")
+	assert.False(t, ok)
+	assert.False(t, tree.Has("/api/user"))
+	assert.True(t, tree.Has("/api/users"))
+
+	key, val, ok := tree.LongestPrefix("/api/users/42")
+	assert.True(t, ok)
+	assert.Equal(t, "/api/users", key.Cast())
+	assert.Equal(t, "/API/USERS", val)
+
+	key, _, _ = tree.LongestPrefix("/about")
+	assert.Equal(t, "/", key.Cast())
+
+	_, _, ok = tree.LongestPrefix("api")
+	assert.False(t, ok)
+
+	assert.Equal(t, []string{"/api", "/api/users", "/api/users/admin", "/app"}, radixKeys(tree.WalkPrefix("/ap")))
+	assert.Equal(t, []string{"/api/users", "/api/users/admin"}, radixKeys(tree.WalkPrefix("/api/u")))
+	assert.Empty(t, radixKeys(tree.WalkPrefix("/b")))
+
+	// removing /api leaves /api/users reachable through a merged edge
+	v, ok := tree.Delete("/api")
+	assert.True(t, ok)
+	assert.Equal(t, "/API", v)
+	_, ok = tree.Delete("/api/users")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"/api/users/admin", "/app"}, radixKeys(tree.WalkPrefix("/ap")))
+
+	key, _, _ = tree.LongestPrefix("/api/users/admin/1")
+	assert.Equal(t, "/api/users/admin", key.Cast())
+}
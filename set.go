@@ -0,0 +1,148 @@
+package alloc
+
+import (
+	"hash/maphash"
+	"iter"
+)
+
+// Set is a hash set stored in the allocator. Like Object, C is the go
+// primitive type of the key, and K is the type stored in the allocator,
+// so a set of strings is a Set[string, String].
+//
+// Set is a small header which you hold onto, the table is stored in the
+// allocator and replaced by a larger one as the set grows.
+type Set[C comparable, K Primitive[C]] struct {
+	table hashTable[K]
+}
+
+// NewSet creates a set in the allocator with room for size keys before it
+// has to grow
+func NewSet[C comparable, K Primitive[C]](a Allocator, size int) (Set[C, K], error) {
+	table, err := newHashTable[K](a, size)
+	if err != nil {
+		return Set[C, K]{}, err
+	}
+
+	return Set[C, K]{table: table}, nil
+}
+
+// find returns the slot holding key and true. If the key isn't in the set
+// the slot it belongs in is returned with false
+func (s *Set[C, K]) find(key C) (int, uint64, bool) {
+	hash := maphash.Comparable(s.table.seed, key)
+	x, found := s.table.find(hash, func(k K) bool { return k.Cast() == key })
+	return x, hash, found
+}
+
+// Add puts the key in the set. Allocation errors are returned when the set
+// has to grow
+func (s *Set[C, K]) Add(key K) error {
+	_, hash, found := s.find(key.Cast())
+	if found {
+		return nil
+	}
+
+	x, err := s.table.reserve(hash)
+	if err != nil {
+		return err
+	}
+
+	s.table.put(x, hash, key)
+	return nil
+}
+
+// Has returns if the key is in the set
+func (s Set[C, K]) Has(key C) bool {
+	_, _, found := s.find(key)
+	return found
+}
+
+// Remove takes the key out of the set, returning true if it was there
+func (s *Set[C, K]) Remove(key C) bool {
+	x, _, found := s.find(key)
+	if !found {
+		return false
+	}
+
+	s.table.delete(x)
+	return true
+}
+
+// Len returns the number of keys in the set
+func (s Set[C, K]) Len() int {
+	return s.table.len
+}
+
+// Clear removes every key from the set, keeping the table
+func (s *Set[C, K]) Clear() {
+	s.table.clear()
+}
+
+// All returns an iterator over the keys in the set, in no particular order
+func (s Set[C, K]) All() iter.Seq[K] {
+	return s.table.all()
+}
+
+// Primitives returns an iterator over the keys in the set as primitives
+func (s Set[C, K]) Primitives() iter.Seq[C] {
+	return func(yield func(C) bool) {
+		for key := range s.All() {
+			if !yield(key.Cast()) {
+				return
+			}
+		}
+	}
+}
+
+// Union creates a new set in the allocator with the keys in either set.
+// The keys are copied as is, so keys which point into another allocator,
+// like String, will still point there
+func (s Set[C, K]) Union(a Allocator, other Set[C, K]) (Set[C, K], error) {
+	out, err := NewSet[C, K](a, s.table.len+other.table.len)
+	if err != nil {
+		return Set[C, K]{}, err
+	}
+
+	for _, set := range [...]Set[C, K]{s, other} {
+		for key := range set.All() {
+			if err := out.Add(key); err != nil {
+				return Set[C, K]{}, err
+			}
+		}
+	}
+
+	return out, nil
+}
+
+// Intersection creates a new set in the allocator with the keys found in
+// both sets
+func (s Set[C, K]) Intersection(a Allocator, other Set[C, K]) (Set[C, K], error) {
+	return s.filter(a, other, true)
+}
+
+// Difference creates a new set in the allocator with the keys in this set
+// which are not in other
+func (s Set[C, K]) Difference(a Allocator, other Set[C, K]) (Set[C, K], error) {
+	return s.filter(a, other, false)
+}
+
+// filter creates a new set with the keys from this set where finding the
+// key in other matches keep
+func (s Set[C, K]) filter(a Allocator, other Set[C, K], keep bool) (Set[C, K], error) {
+	out, err := NewSet[C, K](a, s.table.len)
+	if err != nil {
+		return Set[C, K]{}, err
+	}
+
+	for key := range s.All() {
+		if other.Has(key.Cast()) != keep {
+			continue
+		}
+
+		if err := out.Add(key); err != nil {
+			return Set[C, K]{}, err
+		}
+	}
+
+	return out, nil
+}
@@ -0,0 +1,64 @@
+package alloc
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSet(t *testing.T) {
+	arena := NewExpandingAllocator(8)
+	set := Must(NewSet[int, Int](&arena, 0))
+
+	for x := range 100 {
+		assert.NoError(t, set.Add(Int(x)))
+	}
+	assert.NoError(t, set.Add(Int(5)))
+	assert.Equal(t, 100, set.Len())
+
+	for x := 0; x < 100; x += 2 {
+		assert.True(t, set.Remove(x))
+	}
+	assert.False(t, set.Remove(0))
+	assert.Equal(t, 50, set.Len())
+	assert.False(t, set.Has(0))
+	assert.True(t, set.Has(1))
+
+	// removed keys can be added again
+	assert.NoError(t, set.Add(Int(0)))
+	assert.True(t, set.Has(0))
+
+	keys := slices.Sorted(set.Primitives())
+	assert.Equal(t, 51, len(keys))
+	assert.Equal(t, 0, keys[0])
+	assert.Equal(t, 99, keys[50])
+
+	set.Clear()
+	assert.Equal(t, 0, set.Len())
+	assert.False(t, set.Has(1))
+}
+
+func TestSetOperations(t *testing.T) {
+	arena := NewExpandingAllocator(8)
+	newSet := func(vals ...string) Set[string, String] {
+		set := Must(NewSet[string, String](&arena, len(vals)))
+		for _, v := range vals {
+			set.Add(*Must(NewString(&arena, v)).Deref())
+		}
+
+		return set
+	}
+
+	a := newSet("a", "b", "c")
+	b := newSet("b", "c", "d")
+
+	union := Must(a.Union(&arena, b))
+	assert.Equal(t, []string{"a", "b", "c", "d"}, slices.Sorted(union.Primitives()))
+
+	intersection := Must(a.Intersection(&arena, b))
+	assert.Equal(t, []string{"b", "c"}, slices.Sorted(intersection.Primitives()))
+
+	difference := Must(a.Difference(&arena, b))
+	assert.Equal(t, []string{"a"}, slices.Sorted(difference.Primitives()))
+}
@@ -0,0 +1,218 @@
+package alloc
+
+import (
+	"iter"
+	"math/bits"
+	"math/rand/v2"
+)
+
+// skipListMaxLevel is the most levels a node can be linked into. With a
+// quarter of the nodes moving up each level this is plenty for any list
+// which fits in memory
+const skipListMaxLevel = 24
+
+// skipNode is a single entry in the list. next holds the following node on
+// each level the node is linked into
+type skipNode[K Comparable[K], V any] struct {
+	key  K
+	val  V
+	next Array[Ptr[skipNode[K, V]]]
+}
+
+// SkipList is an ordered map whose nodes are stored in the allocator. Keys
+// must implement Comparable, like String does. Use the primitive types such
+// as Int or Float64 for go numbers.
+//
+// Unlike the BTree, a node never moves once it is inserted and an insert
+// only changes the links around the new node, which makes the skip list a
+// good fit for concurrent use. It is not safe for concurrent use yet, since
+// none of the allocators are.
+//
+// The SkipList is a header pointing to the head node, which links to the
+// first node on every level.
+type SkipList[K Comparable[K], V any] struct {
+	head  Ptr[skipNode[K, V]]
+	level int
+	len   int
+}
+
+// NewSkipList creates a new empty list in the allocator
+func NewSkipList[K Comparable[K], V any](a Allocator) (SkipList[K, V], error) {
+	head, err := newSkipNode[K, V](a, skipListMaxLevel)
+	if err != nil {
+		return SkipList[K, V]{}, err
+	}
+
+	return SkipList[K, V]{head: head}, nil
+}
+
+// newSkipNode creates an unlinked node in the allocator with room for the
+// number of levels
+func newSkipNode[K Comparable[K], V any](a Allocator, level int) (Ptr[skipNode[K, V]], error) {
+	n, err := New[skipNode[K, V]](a)
+	if err != nil {
+		return Ptr[skipNode[K, V]]{}, err
+	}
+
+	next, err := NewArray[Ptr[skipNode[K, V]]](a, level)
+	if err != nil {
+		return Ptr[skipNode[K, V]]{}, err
+	}
+
+	clear(next.Deref().Slice())
+	n.Set(skipNode[K, V]{next: *next.Deref()})
+	return n, nil
+}
+
+// randomLevel picks how many levels a new node is linked into, each level
+// has a one in four chance of the node moving up to the next one
+func randomLevel() int {
+	return min(1+bits.TrailingZeros64(rand.Uint64())/2, skipListMaxLevel)
+}
+
+// Len returns the number of entries in the list
+func (s *SkipList[K, V]) Len() int {
+	return s.len
+}
+
+// search returns the first node with a key not less than key. When path is
+// set it is filled with the last node before key on every level
+func (s *SkipList[K, V]) search(key K, path *[skipListMaxLevel]Ptr[skipNode[K, V]]) Ptr[skipNode[K, V]] {
+	x := s.head
+	for i := s.level - 1; i >= 0; i-- {
+		for {
+			next := x.Deref().next.Slice()[i]
+			if next.IsNull() || next.Deref().key.Cmp(key) >= 0 {
+				break
+			}
+
+			x = next
+		}
+
+		if path != nil {
+			path[i] = x
+		}
+	}
+
+	return x.Deref().next.Slice()[0]
+}
+
+// Get returns the value stored for key, if there is no value the empty
+// value of V and false are returned
+func (s *SkipList[K, V]) Get(key K) (V, bool) {
+	n := s.search(key, nil)
+	if n.IsNull() || n.Deref().key.Cmp(key) != 0 {
+		return *new(V), false
+	}
+
+	return n.Deref().val, true
+}
+
+// Has returns if there is a value stored for key
+func (s *SkipList[K, V]) Has(key K) bool {
+	_, ok := s.Get(key)
+	return ok
+}
+
+// Insert stores val under key, replacing any existing value. Allocation
+// errors are returned when a new node can not be created
+func (s *SkipList[K, V]) Insert(key K, val V) error {
+	var path [skipListMaxLevel]Ptr[skipNode[K, V]]
+
+	n := s.search(key, &path)
+	if !n.IsNull() && n.Deref().key.Cmp(key) == 0 {
+		n.Deref().val = val
+		return nil
+	}
+
+	level := randomLevel()
+	n, err := newSkipNode[K, V](s.head.alloc, level)
+	if err != nil {
+		return err
+	}
+
+	for i := s.level; i < level; i++ {
+		path[i] = s.head
+	}
+	s.level = max(s.level, level)
+
+	node := n.Deref()
+	node.key, node.val = key, val
+	for i := range level {
+		prev := path[i].Deref().next.Slice()
+		node.next.Slice()[i] = prev[i]
+		prev[i] = n
+	}
+
+	s.len++
+	return nil
+}
+
+// Delete removes key from the list, returning the value which was stored
+// and true, or false if the key wasn't in the list. The node's memory is
+// not reclaimed
+func (s *SkipList[K, V]) Delete(key K) (V, bool) {
+	var path [skipListMaxLevel]Ptr[skipNode[K, V]]
+
+	n := s.search(key, &path)
+	if n.IsNull() || n.Deref().key.Cmp(key) != 0 {
+		return *new(V), false
+	}
+
+	node := n.Deref()
+	for i := range node.next.Length() {
+		path[i].Deref().next.Slice()[i] = node.next.Slice()[i]
+	}
+
+	head := s.head.Deref().next.Slice()
+	for s.level > 0 && head[s.level-1].IsNull() {
+		s.level--
+	}
+
+	s.len--
+	return node.val, true
+}
+
+// Seek returns an iterator over the entries from the first key which is not
+// less than key to the end of the list
+func (s *SkipList[K, V]) Seek(key K) iter.Seq2[K, V] {
+	return s.iter(func() Ptr[skipNode[K, V]] {
+		return s.search(key, nil)
+	}, nil)
+}
+
+// Range returns an iterator over the entries with keys from lo up to, but
+// not including, hi
+func (s *SkipList[K, V]) Range(lo, hi K) iter.Seq2[K, V] {
+	return s.iter(func() Ptr[skipNode[K, V]] {
+		return s.search(lo, nil)
+	}, &hi)
+}
+
+// All returns an iterator over every entry in key order
+func (s *SkipList[K, V]) All() iter.Seq2[K, V] {
+	return s.iter(func() Ptr[skipNode[K, V]] {
+		return s.head.Deref().next.Slice()[0]
+	}, nil)
+}
+
+// iter walks the bottom level from the node returned by start, stopping
+// before hi when it is set
+func (s *SkipList[K, V]) iter(start func() Ptr[skipNode[K, V]], hi *K) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for n := start(); !n.IsNull(); {
+			node := n.Deref()
+			if hi != nil && node.key.Cmp(*hi) >= 0 {
+				return
+			}
+
+			if !yield(node.key, node.val) {
+				return
+			}
+
+			// the node could have moved if the allocator grew while
+			// yielding
+			n = n.Deref().next.Slice()[0]
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package alloc
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSkipList(t *testing.T) {
+	arena := NewExpandingAllocator(8)
+	list := Must(NewSkipList[Int, int](&arena))
+
+	_, ok := list.Get(0)
+	assert.False(t, ok)
+
+	// insert and delete in a random order, checking against a map
+	rng := rand.New(rand.NewPCG(1, 2))
+	expected := map[int]int{}
+	for range 5000 {
+		k := rng.IntN(1000)
+		if rng.IntN(3) == 0 {
+			v, ok := list.Delete(Int(k))
+			ev, eok := expected[k]
+			assert.Equal(t, eok, ok)
+			assert.Equal(t, ev, v)
+			delete(expected, k)
+			continue
+		}
+
+		assert.NoError(t, list.Insert(Int(k), k*10))
+		expected[k] = k * 10
+	}
+
+	assert.Equal(t, len(expected), list.Len())
+
+	var want []Int
+	for k, v := range expected {
+		want = append(want, Int(k))
+
+		got, ok := list.Get(Int(k))
+		assert.True(t, ok)
+		assert.Equal(t, v, got)
+	}
+	slices.Sort(want)
+	assert.Equal(t, want, btreeKeys(list.All()))
+
+	for k := range expected {
+		_, ok := list.Delete(Int(k))
+		assert.True(t, ok)
+	}
+
+	assert.Equal(t, 0, list.Len())
+	assert.Empty(t, btreeKeys(list.All()))
+}
+
+func TestSkipListQueries(t *testing.T) {
+	arena := NewExpandingAllocator(8)
+	list := Must(NewSkipList[String, int](&arena))
+	for _, s := range []string{"pear", "apple", "fig", "kiwi", "banana"} {
+		list.Insert(*Must(NewString(&arena, s)).Deref(), len(s))
+	}
+
+	str := func(s string) String {
+		return *Must(NewString(&arena, s)).Deref()
+	}
+
+	var keys []string
+	for k := range list.Seek(str("c")) {
+		keys = append(keys, k.Cast())
+	}
+	assert.Equal(t, []string{"fig", "kiwi", "pear"}, keys)
+
+	keys = nil
+	for k := range list.Range(str("banana"), str("kiwi")) {
+		keys = append(keys, k.Cast())
+	}
+	assert.Equal(t, []string{"banana", "fig"}, keys)
+
+	assert.Empty(t, btreeKeys(list.Seek(str("z"))))
+	assert.True(t, list.Has(str("fig")))
+}
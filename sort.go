@@ -0,0 +1,151 @@
+package alloc
+
+import (
+	"cmp"
+	"slices"
+)
+
+// Sort sorts the array in place in ascending order
+func Sort[T cmp.Ordered](arr Array[T]) {
+	slices.Sort(arr.Slice())
+}
+
+// SortFunc sorts the array in place using cmp, which returns a negative
+// number when a < b, a positive number when a > b and zero when they are
+// equal. For arrays of a Comparable type like String use its Cmp method,
+// SortFunc(arr, String.Cmp)
+func SortFunc[T any](arr Array[T], cmp func(a, b T) int) {
+	slices.SortFunc(arr.Slice(), cmp)
+}
+
+// SortStable sorts the array in place in ascending order, keeping equal
+// elements in their original order
+func SortStable[T cmp.Ordered](arr Array[T]) {
+	slices.SortStableFunc(arr.Slice(), cmp.Compare[T])
+}
+
+// SortStableFunc sorts the array like SortFunc, keeping equal elements in
+// their original order
+func SortStableFunc[T any](arr Array[T], cmp func(a, b T) int) {
+	slices.SortStableFunc(arr.Slice(), cmp)
+}
+
+// IsSorted reports if the array is sorted in ascending order
+func IsSorted[T cmp.Ordered](arr Array[T]) bool {
+	return slices.IsSorted(arr.Slice())
+}
+
+// IsSortedFunc reports if the array is sorted according to cmp
+func IsSortedFunc[T any](arr Array[T], cmp func(a, b T) int) bool {
+	return slices.IsSortedFunc(arr.Slice(), cmp)
+}
+
+// BinarySearch searches the sorted array for target. It returns the index
+// target was found at, or the index it would be inserted at, and if it was
+// found
+func BinarySearch[T cmp.Ordered](arr Array[T], target T) (int, bool) {
+	return slices.BinarySearch(arr.Slice(), target)
+}
+
+// BinarySearchFunc searches the array sorted by cmp for target, like
+// BinarySearch
+func BinarySearchFunc[T, E any](arr Array[T], target E, cmp func(T, E) int) (int, bool) {
+	return slices.BinarySearchFunc(arr.Slice(), target, cmp)
+}
+
+// SortObject sorts the entries of the object by key, keeping each value
+// with its key. Keys are compared as primitives, so an object with String
+// keys is sorted by the bytes of the string. Once sorted, Get, Has, Set
+// and Delete find keys with a binary search until a new key is added. The
+// new order is worked out in a scratch slice of ints on the go heap
+func SortObject[C cmp.Ordered, K Primitive[C], T any](m *Object[C, K, T]) {
+	keys, vals := m.keys.Slice()[:m.len], m.vals.Slice()[:m.len]
+
+	// sort the order of the entries, then move the keys and values into it
+	order := make([]int, len(keys))
+	for x := range order {
+		order[x] = x
+	}
+
+	slices.SortStableFunc(order, func(a, b int) int {
+		return cmp.Compare(keys[a].Cast(), keys[b].Cast())
+	})
+
+	// each entry is moved along the cycle it is part of, marking the
+	// positions which have been filled
+	for start := range order {
+		x := start
+		key, val := keys[start], vals[start]
+		for order[x] != x {
+			next := order[x]
+			order[x] = x
+			if next == start {
+				keys[x], vals[x] = key, val
+				break
+			}
+
+			keys[x], vals[x] = keys[next], vals[next]
+			x = next
+		}
+	}
+
+	m.sorted = true
+}
+
+// search finds key with a binary search over the sorted keys, returning -1
+// if it isn't there. false is returned when C isn't one of the ordered go
+// types, since there is no order to search by
+func (m Object[C, K, T]) search(key C) (int, bool) {
+	if _, ok := compareKeys(key, key); !ok {
+		return 0, false
+	}
+
+	x, found := slices.BinarySearchFunc(m.keys.Slice()[:m.len], key, func(k K, key C) int {
+		c, _ := compareKeys(k.Cast(), key)
+		return c
+	})
+	if !found {
+		return -1, true
+	}
+
+	return x, true
+}
+
+// compareKeys compares two keys when C is one of the ordered go types, the
+// same way cmp.Compare does. Object only requires keys to be comparable, so
+// false is returned for any other type, including types defined with an
+// ordered underlying type
+func compareKeys[C comparable](a, b C) (int, bool) {
+	switch a := any(a).(type) {
+	case string:
+		return cmp.Compare(a, any(b).(string)), true
+	case int:
+		return cmp.Compare(a, any(b).(int)), true
+	case int8:
+		return cmp.Compare(a, any(b).(int8)), true
+	case int16:
+		return cmp.Compare(a, any(b).(int16)), true
+	case int32:
+		return cmp.Compare(a, any(b).(int32)), true
+	case int64:
+		return cmp.Compare(a, any(b).(int64)), true
+	case uint:
+		return cmp.Compare(a, any(b).(uint)), true
+	case uint8:
+		return cmp.Compare(a, any(b).(uint8)), true
+	case uint16:
+		return cmp.Compare(a, any(b).(uint16)), true
+	case uint32:
+		return cmp.Compare(a, any(b).(uint32)), true
+	case uint64:
+		return cmp.Compare(a, any(b).(uint64)), true
+	case uintptr:
+		return cmp.Compare(a, any(b).(uintptr)), true
+	case float32:
+		return cmp.Compare(a, any(b).(float32)), true
+	case float64:
+		return cmp.Compare(a, any(b).(float64)), true
+	}
+
+	return 0, false
+}
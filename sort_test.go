@@ -0,0 +1,159 @@
+package alloc
+
+import (
+	"cmp"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSortArray(t *testing.T) {
+	arena := NewExpandingAllocator(8)
+	arr := *Must(NewArray[int](&arena, 5)).Deref()
+	copy(arr.Slice(), []int{3, 1, 4, 1, 5})
+
+	assert.False(t, IsSorted(arr))
+	Sort(arr)
+	assert.True(t, IsSorted(arr))
+	assert.Equal(t, []int{1, 1, 3, 4, 5}, arr.Slice())
+
+	x, found := BinarySearch(arr, 4)
+	assert.Equal(t, 3, x)
+	assert.True(t, found)
+	x, found = BinarySearch(arr, 2)
+	assert.Equal(t, 2, x)
+	assert.False(t, found)
+
+	floats := *Must(NewArray[float64](&arena, 4)).Deref()
+	copy(floats.Slice(), []float64{2.5, -1, 2.5, 0})
+	SortStable(floats)
+	assert.Equal(t, []float64{-1, 0, 2.5, 2.5}, floats.Slice())
+
+	strs := *Must(NewArray[String](&arena, 3)).Deref()
+	for x, s := range []string{"pear", "fig", "apple"} {
+		strs.Slice()[x] = *Must(NewString(&arena, s)).Deref()
+	}
+
+	SortFunc(strs, String.Cmp)
+	assert.True(t, IsSortedFunc(strs, String.Cmp))
+	assert.Equal(t, "apple", strs.Slice()[0].Cast())
+
+	x, found = BinarySearchFunc(strs, "fig", func(s String, target string) int {
+		return cmp.Compare(s.Cast(), target)
+	})
+	assert.Equal(t, 1, x)
+	assert.True(t, found)
+
+	// stable sorting keeps the order of equal lengths
+	SortStableFunc(strs, func(a, b String) int {
+		return cmp.Compare(a.Len(), b.Len())
+	})
+	var got []string
+	for s := range strs.Iter() {
+		got = append(got, s.Cast())
+	}
+	assert.Equal(t, []string{"fig", "pear", "apple"}, got)
+}
+
+func TestSortObject(t *testing.T) {
+	// the arena is small enough to move as the object grows, and since the
+	// object lives in it, it is only dereferenced once the keys for each
+	// operation have been allocated
+	arena := NewExpandingAllocator(8)
+	obj := Must(NewObject[string, String, int](&arena, 4))
+	for _, x := range []int{42, 7, 19, 3, 88, 51} {
+		key := *Must(NewString(&arena, strconv.Itoa(x))).Deref()
+		assert.NoError(t, obj.Deref().Set(key, x))
+	}
+
+	assert.False(t, obj.Deref().Sorted())
+	SortObject(obj.Deref())
+	assert.True(t, obj.Deref().Sorted())
+	assert.Equal(t, []string{"19", "3", "42", "51", "7", "88"}, slices.Collect(obj.Deref().PrimitiveKeys()))
+
+	// the values moved with their keys
+	for k, v := range obj.Deref().IterPrimitive() {
+		assert.Equal(t, k, strconv.Itoa(v))
+	}
+
+	// a larger shuffled object is sorted with every value kept with its key
+	rng := rand.New(rand.NewPCG(1, 2))
+	shuffled := Must(NewObject[int, Int, int](&arena, 0))
+	for _, x := range rng.Perm(500) {
+		assert.NoError(t, shuffled.Deref().Set(Int(x), -x))
+	}
+
+	SortObject(shuffled.Deref())
+	assert.Equal(t, 500, shuffled.Deref().Len())
+	want := 0
+	for k, v := range shuffled.Deref().IterPrimitive() {
+		assert.Equal(t, want, k)
+		assert.Equal(t, -k, v)
+		want++
+	}
+
+	// sorted objects are searched with a binary search
+	x, ok := obj.Deref().search("51")
+	assert.True(t, ok)
+	assert.Equal(t, 3, x)
+	x, _ = obj.Deref().search("50")
+	assert.Equal(t, -1, x)
+
+	v, ok := obj.Deref().Get("51")
+	assert.True(t, ok)
+	assert.Equal(t, 51, v)
+	_, ok = obj.Deref().Get("50")
+	assert.False(t, ok)
+
+	// deleting keeps the order
+	_, ok = obj.Deref().Delete("42")
+	assert.True(t, ok)
+	assert.True(t, obj.Deref().Sorted())
+	assert.False(t, obj.Deref().Has("42"))
+	assert.True(t, obj.Deref().Has("88"))
+
+	// adding a key means the object has to be searched in order again
+	key := *Must(NewString(&arena, "1")).Deref()
+	assert.NoError(t, obj.Deref().Set(key, 1))
+	assert.False(t, obj.Deref().Sorted())
+	v, ok = obj.Deref().Get("1")
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+
+	ints := Must(NewObject[int, Int, string](&arena, 0))
+	for _, x := range []int{5, -2, 9} {
+		assert.NoError(t, ints.Deref().Set(Int(x), strconv.Itoa(x)))
+	}
+
+	SortObject(ints.Deref())
+	assert.Equal(t, []int{-2, 5, 9}, slices.Collect(ints.Deref().PrimitiveKeys()))
+	s, _ := ints.Deref().Get(9)
+	assert.Equal(t, "9", s)
+	_, ok = ints.Deref().search(9)
+	assert.True(t, ok)
+
+	// keys with a defined type can be sorted, but not searched
+	ids := Must(NewObject[testID, testIDKey, int](&arena, 0))
+	for _, x := range []int{3, 1, 2} {
+		assert.NoError(t, ids.Deref().Set(testIDKey(x), x))
+	}
+
+	SortObject(ids.Deref())
+	_, ok = ids.Deref().search(2)
+	assert.False(t, ok)
+	v, ok = ids.Deref().Get(2)
+	assert.True(t, ok)
+	assert.Equal(t, 2, v)
+}
+
+// testID is a defined type used as the primitive of testIDKey
+type testID int
+
+type testIDKey int
+
+func (i testIDKey) Cast() testID {
+	return testID(i)
+}
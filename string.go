@@ -2,6 +2,10 @@ package alloc
 
 import (
 	"cmp"
+	"iter"
+	"strings"
+	"unicode"
+	"unicode/utf8"
 	"unsafe"
 )
 
@@ -16,7 +20,7 @@ func NewString(alloc Allocator, s string) (Ptr[String], error) {
 func NewStringFromBytes(alloc Allocator, b []byte) (Ptr[String], error) {
 	arr, err := NewArray[byte](alloc, len(b))
 	if err != nil {
-		return Ptr[String]{}, nil
+		return Ptr[String]{}, err
 	}
 
 	copy(arr.Deref().Slice(), b)
@@ -47,3 +51,179 @@ func (s String) Cast() string {
 func (s String) Cmp(val String) int {
 	return cmp.Compare(s.Cast(), val.Cast())
 }
+
+// Len returns the number of bytes in the string
+func (s String) Len() int {
+	return Array[byte](s).Length()
+}
+
+// Sub returns the bytes from start up to end as a new String. No bytes are
+// copied, the new String points into the same underlying bytes
+func (s String) Sub(start, end int) String {
+	return String(Array[byte](s).Sub(start, end))
+}
+
+// Equal reports if both strings hold the same bytes
+func (s String) Equal(val String) bool {
+	return s.Cast() == val.Cast()
+}
+
+// EqualFold reports if both strings are equal under simple unicode case
+// folding, see strings.EqualFold
+func (s String) EqualFold(val String) bool {
+	return strings.EqualFold(s.Cast(), val.Cast())
+}
+
+// HasPrefix reports if the string begins with prefix
+func (s String) HasPrefix(prefix string) bool {
+	return strings.HasPrefix(s.Cast(), prefix)
+}
+
+// HasSuffix reports if the string ends with suffix
+func (s String) HasSuffix(suffix string) bool {
+	return strings.HasSuffix(s.Cast(), suffix)
+}
+
+// Index returns the index of the first instance of substr, or -1 if it
+// isn't found
+func (s String) Index(substr string) int {
+	return strings.Index(s.Cast(), substr)
+}
+
+// LastIndex returns the index of the last instance of substr, or -1 if it
+// isn't found
+func (s String) LastIndex(substr string) int {
+	return strings.LastIndex(s.Cast(), substr)
+}
+
+// Contains reports if substr is within the string
+func (s String) Contains(substr string) bool {
+	return strings.Contains(s.Cast(), substr)
+}
+
+// TrimPrefix returns the string without the leading prefix. If the string
+// doesn't start with prefix it is returned unchanged
+func (s String) TrimPrefix(prefix string) String {
+	if !s.HasPrefix(prefix) {
+		return s
+	}
+
+	return s.Sub(len(prefix), s.Len())
+}
+
+// TrimSuffix returns the string without the trailing suffix. If the string
+// doesn't end with suffix it is returned unchanged
+func (s String) TrimSuffix(suffix string) String {
+	if !s.HasSuffix(suffix) {
+		return s
+	}
+
+	return s.Sub(0, s.Len()-len(suffix))
+}
+
+// TrimSpace returns the string without leading and trailing white space
+func (s String) TrimSpace() String {
+	str := s.Cast()
+	trimmed := strings.TrimLeftFunc(str, unicode.IsSpace)
+	start := len(str) - len(trimmed)
+	end := start + len(strings.TrimRightFunc(trimmed, unicode.IsSpace))
+	return s.Sub(start, end)
+}
+
+// Split returns an iterator of the substrings between each instance of sep.
+// Each substring points into the same underlying bytes. If sep is empty the
+// string is split after each UTF-8 sequence, this behaves like strings.Split
+func (s String) Split(sep string) iter.Seq[String] {
+	return func(yield func(String) bool) {
+		str := s.Cast()
+		if sep == "" {
+			for start := 0; start < len(str); {
+				_, size := utf8.DecodeRuneInString(str[start:])
+				if !yield(s.Sub(start, start+size)) {
+					return
+				}
+
+				start += size
+			}
+
+			return
+		}
+
+		for start := 0; ; {
+			x := strings.Index(str[start:], sep)
+			if x == -1 {
+				yield(s.Sub(start, len(str)))
+				return
+			}
+
+			if !yield(s.Sub(start, start+x)) {
+				return
+			}
+
+			start += x + len(sep)
+		}
+	}
+}
+
+// Runes returns an iterator of the runes in the string and the byte index
+// they start at. Invalid UTF-8 is returned as utf8.RuneError
+func (s String) Runes() iter.Seq2[int, rune] {
+	return func(yield func(int, rune) bool) {
+		for x, r := range s.Cast() {
+			if !yield(x, r) {
+				return
+			}
+		}
+	}
+}
+
+// Concat creates a new String in the allocator holding the string followed
+// by each of vals
+func (s String) Concat(alloc Allocator, vals ...String) (Ptr[String], error) {
+	size := s.Len()
+	for _, val := range vals {
+		size += val.Len()
+	}
+
+	arr, err := NewArray[byte](alloc, size)
+	if err != nil {
+		return Ptr[String]{}, err
+	}
+
+	b := arr.Deref().Slice()
+	n := copy(b, s.Cast())
+	for _, val := range vals {
+		n += copy(b[n:], val.Cast())
+	}
+
+	return Ptr[String](arr), nil
+}
+
+// ToLower creates a new String in the allocator with every letter mapped
+// to lower case
+func (s String) ToLower(alloc Allocator) (Ptr[String], error) {
+	return s.mapRunes(alloc, unicode.ToLower)
+}
+
+// ToUpper creates a new String in the allocator with every letter mapped
+// to upper case
+func (s String) ToUpper(alloc Allocator) (Ptr[String], error) {
+	return s.mapRunes(alloc, unicode.ToUpper)
+}
+
+// mapRunes creates a new String in the allocator with each rune replaced
+// by the result of fn
+func (s String) mapRunes(alloc Allocator, fn func(rune) rune) (Ptr[String], error) {
+	b, err := NewBuffer(alloc, s.Len())
+	if err != nil {
+		return Ptr[String]{}, err
+	}
+
+	for _, r := range s.Runes() {
+		if _, err := b.WriteRune(fn(r)); err != nil {
+			return Ptr[String]{}, err
+		}
+	}
+
+	return b.NewString()
+}
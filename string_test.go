@@ -0,0 +1,85 @@
+package alloc
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStringViews(t *testing.T) {
+	arena := NewExpandingAllocator(8)
+	s := *Must(NewString(&arena, "  hello, world  ")).Deref()
+
+	trimmed := s.TrimSpace()
+	assert.Equal(t, "hello, world", trimmed.Cast())
+	assert.Equal(t, 12, trimmed.Len())
+	assert.Equal(t, "world", trimmed.Sub(7, 12).Cast())
+	assert.Equal(t, "", trimmed.Sub(12, 12).Cast())
+	assert.Panics(t, func() { trimmed.Sub(3, 13) })
+
+	// views point into the same bytes
+	Array[byte](trimmed).Slice()[0] = 'j'
+	assert.Equal(t, "  jello, world  ", s.Cast())
+
+	assert.True(t, trimmed.HasPrefix("jello"))
+	assert.True(t, trimmed.HasSuffix("world"))
+	assert.True(t, trimmed.Contains(", "))
+	assert.Equal(t, 3, trimmed.Index("lo"))
+	assert.Equal(t, 10, trimmed.LastIndex("l"))
+	assert.Equal(t, "lo, world", trimmed.TrimPrefix("jel").Cast())
+	assert.Equal(t, "jello, ", trimmed.TrimSuffix("world").Cast())
+	assert.Equal(t, "jello, world", trimmed.TrimSuffix("nope").Cast())
+	assert.Equal(t, "", (*Must(NewString(&arena, " \t\n")).Deref()).TrimSpace().Cast())
+}
+
+func TestStringSplit(t *testing.T) {
+	arena := NewExpandingAllocator(8)
+	split := func(s, sep string) []string {
+		var out []string
+		for part := range Must(NewString(&arena, s)).Deref().Split(sep) {
+			out = append(out, part.Cast())
+		}
+
+		return out
+	}
+
+	assert.Equal(t, []string{"a", "b", "", "c"}, split("a,b,,c", ","))
+	assert.Equal(t, []string{"a", "b"}, split("a::b", "::"))
+	assert.Equal(t, []string{""}, split("", ","))
+	assert.Equal(t, []string{"", ""}, split(",", ","))
+	assert.Equal(t, []string{"h", "é", "!"}, split("hé!", ""))
+}
+
+func TestStringRunes(t *testing.T) {
+	arena := NewExpandingAllocator(8)
+	s := *Must(NewString(&arena, "hé!")).Deref()
+
+	var indexes []int
+	var runes []rune
+	for x, r := range s.Runes() {
+		indexes = append(indexes, x)
+		runes = append(runes, r)
+	}
+
+	assert.Equal(t, []int{0, 1, 3}, indexes)
+	assert.Equal(t, []rune{'h', 'é', '!'}, runes)
+	assert.Equal(t, []String{s.Sub(0, 1)}, slices.Collect(s.Sub(0, 1).Split(",")))
+}
+
+func TestStringTransforms(t *testing.T) {
+	arena := NewExpandingAllocator(8)
+	hello := *Must(NewString(&arena, "Hello")).Deref()
+	world := *Must(NewString(&arena, "WÖRLD")).Deref()
+
+	joined := *Must(hello.Concat(&arena, *Must(NewString(&arena, ", ")).Deref(), world)).Deref()
+	assert.Equal(t, "Hello, WÖRLD", joined.Cast())
+	assert.Equal(t, "hello, wörld", Must(joined.ToLower(&arena)).Deref().Cast())
+	assert.Equal(t, "HELLO, WÖRLD", Must(joined.ToUpper(&arena)).Deref().Cast())
+	assert.Equal(t, "Hello", Must(hello.Concat(&arena)).Deref().Cast())
+
+	lower := *Must(world.ToLower(&arena)).Deref()
+	assert.False(t, lower.Equal(world))
+	assert.True(t, lower.EqualFold(world))
+	assert.True(t, world.Equal(*Must(NewString(&arena, "WÖRLD")).Deref()))
+}
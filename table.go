@@ -0,0 +1,163 @@
+package alloc
+
+import (
+	"hash/maphash"
+	"iter"
+)
+
+// hashSlot is a single entry in a hashTable
+type hashSlot[E any] struct {
+	hash  uint64
+	full  bool
+	entry E
+}
+
+// hashTable is an open addressed hash table for the hash based containers.
+// It only knows the hash of each entry, so finding an entry takes a
+// function which checks if an entry is the one being looked for. The slots
+// are stored in the allocator and replaced by a larger table as it grows.
+//
+// Removing an entry shifts back the entries which probed past it, so the
+// table never fills up with deleted slots.
+type hashTable[E any] struct {
+	seed  maphash.Seed
+	slots Array[hashSlot[E]]
+	len   int
+}
+
+// newHashTable creates an empty table in the allocator with room for size
+// entries before it has to grow
+func newHashTable[E any](a Allocator, size int) (hashTable[E], error) {
+	slots, err := newHashSlots[E](a, size)
+	if err != nil {
+		return hashTable[E]{}, err
+	}
+
+	return hashTable[E]{seed: maphash.MakeSeed(), slots: slots}, nil
+}
+
+// newHashSlots creates empty slots large enough to hold size entries while
+// staying under the maximum load
+func newHashSlots[E any](a Allocator, size int) (Array[hashSlot[E]], error) {
+	n := 8
+	for n*3/4 < size {
+		n *= 2
+	}
+
+	slots, err := NewArray[hashSlot[E]](a, n)
+	if err != nil {
+		return Array[hashSlot[E]]{}, err
+	}
+
+	// mark every slot empty
+	clear(slots.Deref().Slice())
+	return *slots.Deref(), nil
+}
+
+// find returns the slot holding the entry with the hash which eq returns
+// true for. If there isn't one the empty slot where it belongs is returned
+// with false
+func (t *hashTable[E]) find(hash uint64, eq func(E) bool) (int, bool) {
+	slots := t.slots.Slice()
+	mask := uint64(len(slots) - 1)
+
+	for x := hash & mask; ; x = (x + 1) & mask {
+		slot := &slots[x]
+		if !slot.full {
+			return int(x), false
+		}
+
+		if slot.hash == hash && eq(slot.entry) {
+			return int(x), true
+		}
+	}
+}
+
+// reserve makes room for one more entry, growing the table when it would
+// get too full, and returns the empty slot where an entry with the hash
+// belongs. The entry must not already be in the table. The table is left
+// as it was if it has to grow and the new slots can't be allocated
+func (t *hashTable[E]) reserve(hash uint64) (int, error) {
+	if t.len+1 > t.slots.Length()*3/4 {
+		if err := t.grow(); err != nil {
+			return 0, err
+		}
+	}
+
+	x, _ := t.find(hash, func(E) bool { return false })
+	return x, nil
+}
+
+// put stores the entry in the empty slot x returned by find or reserve
+func (t *hashTable[E]) put(x int, hash uint64, entry E) {
+	t.slots.Slice()[x] = hashSlot[E]{hash: hash, full: true, entry: entry}
+	t.len++
+}
+
+// at returns the entry in slot x
+func (t *hashTable[E]) at(x int) *E {
+	return &t.slots.Slice()[x].entry
+}
+
+// delete empties slot x, shifting back the entries after it which probed
+// past it
+func (t *hashTable[E]) delete(x int) {
+	slots := t.slots.Slice()
+	mask := len(slots) - 1
+
+	for y := (x + 1) & mask; slots[y].full; y = (y + 1) & mask {
+		// the entry in y can only move back to x if x isn't before the
+		// slot it hashed to
+		home := int(slots[y].hash) & mask
+		if (y-home)&mask >= (y-x)&mask {
+			slots[x] = slots[y]
+			x = y
+		}
+	}
+
+	slots[x] = hashSlot[E]{}
+	t.len--
+}
+
+// grow moves every entry into new slots with room for twice as many
+func (t *hashTable[E]) grow() error {
+	slots, err := newHashSlots[E](t.slots.data.alloc, t.len*2)
+	if err != nil {
+		return err
+	}
+
+	mask := uint64(slots.Length() - 1)
+	for _, slot := range t.slots.Slice() {
+		if !slot.full {
+			continue
+		}
+
+		y := slot.hash & mask
+		for slots.Slice()[y].full {
+			y = (y + 1) & mask
+		}
+
+		slots.Slice()[y] = slot
+	}
+
+	t.slots = slots
+	return nil
+}
+
+// clear removes every entry, keeping the slots
+func (t *hashTable[E]) clear() {
+	clear(t.slots.Slice())
+	t.len = 0
+}
+
+// all returns an iterator over the entries in slot order
+func (t *hashTable[E]) all() iter.Seq[E] {
+	return func(yield func(E) bool) {
+		for x := range t.slots.Length() {
+			slot := t.slots.Slice()[x]
+			if slot.full && !yield(slot.entry) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package alloc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashTable(t *testing.T) {
+	arena := NewExpandingAllocator(8)
+	table := Must(newHashTable[int](&arena, 0))
+	eq := func(want int) func(int) bool {
+		return func(v int) bool { return v == want }
+	}
+
+	// every value collides, apart from 3 which lands in the middle of them
+	hash := func(v int) uint64 {
+		if v == 3 {
+			return 1
+		}
+
+		return 0
+	}
+
+	for v := range 5 {
+		x, err := table.reserve(hash(v))
+		assert.NoError(t, err)
+		table.put(x, hash(v), v)
+	}
+
+	assert.Equal(t, 5, table.len)
+	assert.Equal(t, 8, table.slots.Length())
+
+	// removing the first value shifts the others back so they can still be
+	// found, without moving 3 in front of the slot it hashed to
+	x, found := table.find(hash(0), eq(0))
+	assert.True(t, found)
+	table.delete(x)
+
+	for v := 1; v < 5; v++ {
+		_, found := table.find(hash(v), eq(v))
+		assert.True(t, found, v)
+	}
+
+	_, found = table.find(hash(0), eq(0))
+	assert.False(t, found)
+
+	// growing keeps every value
+	for v := 5; v < 100; v++ {
+		x, err := table.reserve(hash(v))
+		assert.NoError(t, err)
+		table.put(x, hash(v), v)
+	}
+
+	assert.Equal(t, 99, table.len)
+	for v := 1; v < 100; v++ {
+		_, found := table.find(hash(v), eq(v))
+		assert.True(t, found, v)
+	}
+
+	table.clear()
+	assert.Equal(t, 0, table.len)
+	for range table.all() {
+		t.Fatal("the table should be empty")
+	}
+}
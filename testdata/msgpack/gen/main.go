@@ -0,0 +1,227 @@
+// gen writes the msgpack fixtures used by TestMsgpackReference. Each value
+// is encoded by github.com/vmihailenco/msgpack into <name>.msgpack, with the
+// value described in <name>.json so the test knows what to expect after
+// decoding. Values are encoded a second time with the smallest ints into
+// <name>.compact.msgpack when that changes the bytes. Compact floats aren't
+// used, since they write whole floats as ints.
+//
+// Run it from this directory with
+//
+//	go run . ..
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// fixture is a value to encode. Maps are written as ordered pairs so the
+// encoding and the description agree on the key order
+type fixture struct {
+	name  string
+	value any
+}
+
+// pairs is a map with a fixed key order
+type pairs [][2]any
+
+func (p pairs) EncodeMsgpack(enc *msgpack.Encoder) error {
+	if err := enc.EncodeMapLen(len(p)); err != nil {
+		return err
+	}
+
+	for _, kv := range p {
+		if err := enc.Encode(kv[0]); err != nil {
+			return err
+		}
+
+		if err := enc.Encode(kv[1]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func fixtures() []fixture {
+	many := make([]any, 16)
+	for x := range many {
+		many[x] = x
+	}
+
+	wide := make(pairs, 16)
+	for x := range wide {
+		wide[x] = [2]any{fmt.Sprintf("k%02d", x), x}
+	}
+
+	return []fixture{
+		{"nil", nil},
+		{"true", true},
+		{"false", false},
+		{"int_0", int64(0)},
+		{"int_127", int64(127)},
+		{"int_128", int64(128)},
+		{"int_255", int64(255)},
+		{"int_256", int64(256)},
+		{"int_65535", int64(65535)},
+		{"int_65536", int64(65536)},
+		{"int_4294967296", int64(1 << 32)},
+		{"int_max", int64(math.MaxInt64)},
+		{"int_neg_1", int64(-1)},
+		{"int_neg_32", int64(-32)},
+		{"int_neg_33", int64(-33)},
+		{"int_neg_128", int64(-128)},
+		{"int_neg_129", int64(-129)},
+		{"int_neg_32769", int64(-32769)},
+		{"int_min", int64(math.MinInt64)},
+		{"uint8", uint8(200)},
+		{"uint16", uint16(60000)},
+		{"uint32", uint32(4000000000)},
+		{"uint64_high", uint64(1 << 63)},
+		{"uint64_max", uint64(math.MaxUint64)},
+		{"int8", int8(-100)},
+		{"int16", int16(-30000)},
+		{"int32", int32(-2000000000)},
+		{"float32", float32(0.5)},
+		{"float32_pi", float32(math.Pi)},
+		{"float64", 0.1},
+		{"float64_half", 0.5},
+		{"float64_neg_zero", math.Copysign(0, -1)},
+		{"float64_inf", math.Inf(1)},
+		{"float64_max", math.MaxFloat64},
+		{"float64_whole", 3.0},
+		{"str_empty", ""},
+		{"str_fix", "hello"},
+		{"str_31", strings.Repeat("a", 31)},
+		{"str_32", strings.Repeat("b", 32)},
+		{"str_255", strings.Repeat("c", 255)},
+		{"str_256", strings.Repeat("d", 256)},
+		{"str_65536", strings.Repeat("e", 1<<16)},
+		{"str_unicode", "héllo 世界 🚀"},
+		{"bin_empty", []byte{}},
+		{"bin_small", []byte{1, 2, 3}},
+		{"bin_256", bytes.Repeat([]byte{0xab}, 256)},
+		{"bin_65536", bytes.Repeat([]byte{0xcd}, 1<<16)},
+		{"array_empty", []any{}},
+		{"array_mixed", []any{1, "a", nil, true, 1.5}},
+		{"array_16", many},
+		{"map_empty", pairs{}},
+		{"map_small", pairs{{"a", 1}, {"b", false}}},
+		{"map_16", wide},
+		{"nested", pairs{
+			{"users", []any{
+				pairs{{"name", "alice"}, {"age", 30}, {"tags", []any{"admin", "ops"}}},
+				pairs{{"name", "bob"}, {"age", -1}, {"avatar", []byte{0x89, 'P', 'N', 'G'}}},
+			}},
+			{"count", 2},
+			{"ratio", 0.75},
+			{"meta", pairs{{"empty", pairs{}}, {"none", nil}}},
+		}},
+	}
+}
+
+// describe turns a value into the form the test builds from a decoded
+// Value. Every scalar is tagged with the kind we expect it to decode as
+func describe(v any) any {
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case bool:
+		return map[string]any{"bool": v}
+	case int:
+		return describe(int64(v))
+	case int8:
+		return describe(int64(v))
+	case int16:
+		return describe(int64(v))
+	case int32:
+		return describe(int64(v))
+	case int64:
+		return map[string]any{"int": strconv.FormatInt(v, 10)}
+	case uint8:
+		return describe(uint64(v))
+	case uint16:
+		return describe(uint64(v))
+	case uint32:
+		return describe(uint64(v))
+	case uint64:
+		// values which fit in an int64 decode as ints
+		if v <= math.MaxInt64 {
+			return describe(int64(v))
+		}
+
+		return map[string]any{"uint": strconv.FormatUint(v, 10)}
+	case float32:
+		return describe(float64(v))
+	case float64:
+		return map[string]any{"float": strconv.FormatFloat(v, 'g', -1, 64)}
+	case string:
+		return map[string]any{"str": v}
+	case []byte:
+		return map[string]any{"bin": v}
+	case []any:
+		out := make([]any, len(v))
+		for x, e := range v {
+			out[x] = describe(e)
+		}
+
+		return map[string]any{"array": out}
+	case pairs:
+		out := make([]any, len(v))
+		for x, kv := range v {
+			out[x] = []any{kv[0], describe(kv[1])}
+		}
+
+		return map[string]any{"map": out}
+	}
+
+	panic(fmt.Sprintf("can not describe %T", v))
+}
+
+func encode(v any, compact bool) []byte {
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	enc.UseCompactInts(compact)
+	if err := enc.Encode(v); err != nil {
+		panic(err)
+	}
+
+	return buf.Bytes()
+}
+
+func main() {
+	dir := "."
+	if len(os.Args) > 1 {
+		dir = os.Args[1]
+	}
+
+	for _, f := range fixtures() {
+		desc, err := json.Marshal(describe(f.value))
+		if err != nil {
+			panic(err)
+		}
+
+		write(filepath.Join(dir, f.name+".json"), append(desc, '\n'))
+
+		wide := encode(f.value, false)
+		write(filepath.Join(dir, f.name+".msgpack"), wide)
+
+		if compact := encode(f.value, true); !bytes.Equal(compact, wide) {
+			write(filepath.Join(dir, f.name+".compact.msgpack"), compact)
+		}
+	}
+}
+
+func write(path string, b []byte) {
+	if err := os.WriteFile(path, b, 0o644); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,118 @@
+package alloc
+
+import "iter"
+
+// Pair holds two values, it is the element type of a zipped Array
+type Pair[T, U any] struct {
+	First  T
+	Second U
+}
+
+// Map creates a new Array in the allocator holding the result of f for
+// each element of src. f is free to allocate from the same allocator
+func Map[T, U any](a Allocator, src Array[T], f func(T) U) (Ptr[Array[U]], error) {
+	dst, err := NewArray[U](a, src.len)
+	if err != nil {
+		return Ptr[Array[U]]{}, err
+	}
+
+	out := *dst.Deref()
+	for x := range src.len {
+		// f could grow the allocator, so only deref after calling it
+		val := f(*src.ptr(x).Deref())
+		out.ptr(x).Set(val)
+	}
+
+	return dst, nil
+}
+
+// Filter creates a new Array in the allocator holding the elements of src
+// for which f returns true. Room for every element of src is allocated, the
+// unused space at the end is not reclaimed
+func Filter[T any](a Allocator, src Array[T], f func(T) bool) (Ptr[Array[T]], error) {
+	dst, err := NewArray[T](a, src.len)
+	if err != nil {
+		return Ptr[Array[T]]{}, err
+	}
+
+	out, n := *dst.Deref(), 0
+	for x := range src.len {
+		val := *src.ptr(x).Deref()
+		if f(val) {
+			out.ptr(n).Set(val)
+			n++
+		}
+	}
+
+	dst.Set(out.Sub(0, n))
+	return dst, nil
+}
+
+// Reduce calls f with the result so far and each element of src, starting
+// with init, and returns the final result
+func Reduce[T, A any](src Array[T], init A, f func(A, T) A) A {
+	acc := init
+	for x := range src.len {
+		acc = f(acc, *src.ptr(x).Deref())
+	}
+
+	return acc
+}
+
+// Chunk returns an iterator over views of src with size elements, the
+// last view may be shorter. No elements are copied
+func Chunk[T any](src Array[T], size int) iter.Seq[Array[T]] {
+	if size < 1 {
+		panic("chunk size must be greater than zero")
+	}
+
+	return func(yield func(Array[T]) bool) {
+		for x := 0; x < src.len; x += size {
+			if !yield(src.Sub(x, min(x+size, src.len))) {
+				return
+			}
+		}
+	}
+}
+
+// Zip creates a new Array in the allocator pairing each element of first
+// with the element at the same index in second. The result is as long as
+// the shorter array
+func Zip[T, U any](a Allocator, first Array[T], second Array[U]) (Ptr[Array[Pair[T, U]]], error) {
+	dst, err := NewArray[Pair[T, U]](a, min(first.len, second.len))
+	if err != nil {
+		return Ptr[Array[Pair[T, U]]]{}, err
+	}
+
+	out := *dst.Deref()
+	for x := range out.len {
+		out.ptr(x).Set(Pair[T, U]{First: *first.ptr(x).Deref(), Second: *second.ptr(x).Deref()})
+	}
+
+	return dst, nil
+}
+
+// Collect creates a new Array in the allocator holding every value from
+// seq. The array is grown by doubling as values arrive, and the smaller
+// arrays left behind are not reclaimed
+func Collect[T any](a Allocator, seq iter.Seq[T]) (Ptr[Array[T]], error) {
+	buf, err := NewArray[T](a, 8)
+	if err != nil {
+		return Ptr[Array[T]]{}, err
+	}
+
+	out, n := *buf.Deref(), 0
+	for val := range seq {
+		if n == out.len {
+			if out, err = out.Expand(out.len * 2); err != nil {
+				return Ptr[Array[T]]{}, err
+			}
+		}
+
+		out.ptr(n).Set(val)
+		n++
+	}
+
+	buf.Set(out.Sub(0, n))
+	return buf, nil
+}
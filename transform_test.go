@@ -0,0 +1,55 @@
+package alloc
+
+import (
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransform(t *testing.T) {
+	arena := NewExpandingAllocator(8)
+	nums := *Must(NewArray[int](&arena, 7)).Deref()
+	copy(nums.Slice(), []int{1, 2, 3, 4, 5, 6, 7})
+
+	// allocating in the same arena while mapping is fine
+	strs := *Must(Map(&arena, nums, func(x int) String {
+		return *Must(NewString(&arena, strconv.Itoa(x*x))).Deref()
+	})).Deref()
+	var got []string
+	for s := range strs.Iter() {
+		got = append(got, s.Cast())
+	}
+	assert.Equal(t, []string{"1", "4", "9", "16", "25", "36", "49"}, got)
+
+	even := *Must(Filter(&arena, nums, func(x int) bool { return x%2 == 0 })).Deref()
+	assert.Equal(t, []int{2, 4, 6}, even.Slice())
+	none := *Must(Filter(&arena, nums, func(int) bool { return false })).Deref()
+	assert.Equal(t, 0, none.Length())
+
+	sum := Reduce(nums, 0, func(acc, x int) int { return acc + x })
+	assert.Equal(t, 28, sum)
+
+	var chunks [][]int
+	for c := range Chunk(nums, 3) {
+		chunks = append(chunks, c.Slice())
+	}
+	assert.Equal(t, [][]int{{1, 2, 3}, {4, 5, 6}, {7}}, chunks)
+	assert.Panics(t, func() { Chunk(nums, 0) })
+
+	zipped := *Must(Zip(&arena, nums, even)).Deref()
+	assert.Equal(t, []Pair[int, int]{{1, 2}, {2, 4}, {3, 6}}, zipped.Slice())
+
+	// collect grows past the starting size
+	collected := *Must(Collect(&arena, slices.Values(make([]int, 100)))).Deref()
+	assert.Equal(t, 100, collected.Length())
+	squares := *Must(Collect(&arena, func(yield func(int) bool) {
+		for x := range nums.Iter() {
+			if !yield(x * x) {
+				return
+			}
+		}
+	})).Deref()
+	assert.Equal(t, []int{1, 4, 9, 16, 25, 36, 49}, squares.Slice())
+}
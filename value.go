@@ -0,0 +1,217 @@
+package alloc
+
+import "math"
+
+// Kind describes which type of data a Value is holding
+type Kind uint8
+
+const (
+	KindNull Kind = iota
+	KindBool
+	KindInt
+	KindUint
+	KindFloat
+	KindString
+	KindArray
+	KindObject
+	KindBytes
+)
+
+// String returns the name of the kind
+func (k Kind) String() string {
+	switch k {
+	case KindNull:
+		return "null"
+	case KindBool:
+		return "bool"
+	case KindInt:
+		return "int"
+	case KindUint:
+		return "uint"
+	case KindFloat:
+		return "float"
+	case KindString:
+		return "string"
+	case KindArray:
+		return "array"
+	case KindObject:
+		return "object"
+	case KindBytes:
+		return "bytes"
+	default:
+		return "unknown"
+	}
+}
+
+// ValueObject is the object type used within a Value tree, keys are
+// Strings and the values are more Values
+type ValueObject = Object[string, String, Value]
+
+// Value is a dynamically typed value which can be stored in the allocator.
+// It is used to represent decoded documents (think JSON) where the shape of
+// the data is not known ahead of time. Arrays and objects hold more Values
+// which lets you build up a tree of data within the allocator. The zero
+// value is null.
+type Value struct {
+	kind Kind
+	// bits holds the bool, int, uint and float values
+	bits uint64
+	// str holds both strings and bytes
+	str String
+	arr Array[Value]
+	obj Ptr[ValueObject]
+}
+
+// NullValue returns a null Value
+func NullValue() Value {
+	return Value{}
+}
+
+// BoolValue returns a Value holding b
+func BoolValue(b bool) Value {
+	v := Value{kind: KindBool}
+	if b {
+		v.bits = 1
+	}
+
+	return v
+}
+
+// IntValue returns a Value holding i
+func IntValue(i int64) Value {
+	return Value{kind: KindInt, bits: uint64(i)}
+}
+
+// UintValue returns a Value holding u
+func UintValue(u uint64) Value {
+	return Value{kind: KindUint, bits: u}
+}
+
+// FloatValue returns a Value holding f
+func FloatValue(f float64) Value {
+	return Value{kind: KindFloat, bits: math.Float64bits(f)}
+}
+
+// StringValue returns a Value holding the String s. The bytes are not
+// copied, so s should already live in the allocator
+func StringValue(s String) Value {
+	return Value{kind: KindString, str: s}
+}
+
+// BytesValue returns a Value holding the raw bytes b. The bytes are not
+// copied, so b should already live in the allocator
+func BytesValue(b Array[byte]) Value {
+	return Value{kind: KindBytes, str: String(b)}
+}
+
+// ArrayValue returns a Value holding the array arr
+func ArrayValue(arr Array[Value]) Value {
+	return Value{kind: KindArray, arr: arr}
+}
+
+// ObjectValue returns a Value holding the object obj. A Ptr is held so
+// the object can continue to grow after it has been placed in the Value
+func ObjectValue(obj Ptr[ValueObject]) Value {
+	return Value{kind: KindObject, obj: obj}
+}
+
+// Kind returns the kind of data stored in the value
+func (v Value) Kind() Kind {
+	return v.kind
+}
+
+// IsNull returns if the value is null
+func (v Value) IsNull() bool {
+	return v.kind == KindNull
+}
+
+// Bool returns the bool stored in the value, false is returned if the
+// value is not a bool
+func (v Value) Bool() bool {
+	return v.kind == KindBool && v.bits != 0
+}
+
+// Int returns the int stored in the value, 0 is returned if the value
+// is not an int
+func (v Value) Int() int64 {
+	if v.kind != KindInt {
+		return 0
+	}
+
+	return int64(v.bits)
+}
+
+// Uint returns the uint stored in the value, 0 is returned if the value
+// is not a uint
+func (v Value) Uint() uint64 {
+	if v.kind != KindUint {
+		return 0
+	}
+
+	return v.bits
+}
+
+// Float returns the float stored in the value, 0 is returned if the value
+// is not a float
+func (v Value) Float() float64 {
+	if v.kind != KindFloat {
+		return 0
+	}
+
+	return math.Float64frombits(v.bits)
+}
+
+// Str returns the String stored in the value, an empty String is returned
+// if the value is not a string
+func (v Value) Str() String {
+	if v.kind != KindString {
+		return String{}
+	}
+
+	return v.str
+}
+
+// Bytes returns the bytes stored in the value, an empty array is returned
+// if the value is not bytes
+func (v Value) Bytes() Array[byte] {
+	if v.kind != KindBytes {
+		return Array[byte]{}
+	}
+
+	return Array[byte](v.str)
+}
+
+// Array returns the array stored in the value, an empty array is returned
+// if the value is not an array
+func (v Value) Array() Array[Value] {
+	if v.kind != KindArray {
+		return Array[Value]{}
+	}
+
+	return v.arr
+}
+
+// Object returns a pointer to the object stored in the value, a null
+// pointer is returned if the value is not an object
+func (v Value) Object() Ptr[ValueObject] {
+	if v.kind != KindObject {
+		return Ptr[ValueObject]{}
+	}
+
+	return v.obj
+}
+
+// number returns the value as a float64 if it is one of the numeric
+// kinds. This is used when comparing numbers of different kinds
+func (v Value) number() (float64, bool) {
+	switch v.kind {
+	case KindInt:
+		return float64(int64(v.bits)), true
+	case KindUint:
+		return float64(v.bits), true
+	case KindFloat:
+		return math.Float64frombits(v.bits), true
+	default:
+		return 0, false
+	}
+}